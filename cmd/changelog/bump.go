@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"changelog-generator/internal/lib"
+
+	"github.com/spf13/cobra"
+)
+
+// Flags for bump command
+var (
+	bumpPre    string
+	bumpDryRun bool
+)
+
+// bumpCmd represents the bump command
+var bumpCmd = &cobra.Command{
+	Use:   "bump [version]",
+	Short: "Update the version in project files",
+	Long: `Write a version into every file listed under version_targets.
+
+Without an argument the next version is calculated from the commits since
+the last release. Each target must match exactly once; if any target fails,
+no file is changed.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := lib.LoadConfig(".changelogrc.yaml")
+		if err != nil {
+			fmt.Printf(" Error loading config: %v\n", err)
+			fmt.Println()
+			fmt.Println(" Tip: Run 'changelog init' to create a config file")
+			os.Exit(1)
+		}
+
+		if len(config.VersionTargets) == 0 {
+			fmt.Println(" No version_targets configured in .changelogrc.yaml")
+			os.Exit(1)
+		}
+
+		scheme, err := lib.NewVersionScheme(config)
+		if err != nil {
+			fmt.Printf(" Error in versioning config: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Use the given version, or calculate the next one
+		var version string
+		if len(args) == 1 {
+			parsed, err := scheme.Parse(strings.TrimPrefix(args[0], config.Versioning.TagPrefix))
+			if err != nil {
+				fmt.Printf(" Error: %v\n", err)
+				os.Exit(1)
+			}
+			version = parsed.String()
+		} else {
+			repo, err := lib.OpenRepository(config.Git.RepositoryPath)
+			if err != nil {
+				fmt.Printf(" Error opening repository: %v\n", err)
+				os.Exit(1)
+			}
+			classifier, err := lib.NewClassifier(repo, config)
+			if err != nil {
+				fmt.Printf(" Error in categorization config: %v\n", err)
+				os.Exit(1)
+			}
+			result, err := lib.CalculateNextVersion(repo, lib.NextVersionOptions{
+				TagPrefix:  config.Versioning.TagPrefix,
+				Scheme:     scheme,
+				PreRelease: bumpPre,
+				Classifier: classifier,
+			})
+			if err != nil {
+				fmt.Printf(" Error calculating version: %v\n", err)
+				os.Exit(1)
+			}
+			version = result.Next.String()
+		}
+
+		changes, err := lib.PlanVersionTargets(config.VersionTargets, version)
+		if err != nil {
+			fmt.Printf(" Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if bumpDryRun {
+			for _, change := range changes {
+				fmt.Print(lib.UnifiedDiff("a/"+change.Path, "b/"+change.Path, change.Original, change.Updated))
+			}
+			return
+		}
+
+		if err := lib.ApplyTargetChanges(changes); err != nil {
+			fmt.Printf(" Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, change := range changes {
+			fmt.Printf(" Updated %s: %s -> %s\n", change.Path, strings.Join(change.Found, ", "), version)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(bumpCmd)
+
+	bumpCmd.Flags().StringVar(&bumpPre, "pre", "", "Pre-release channel (e.g. alpha, beta, rc)")
+	bumpCmd.Flags().BoolVar(&bumpDryRun, "dry-run", false, "Show the changes without writing them")
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"changelog-generator/internal/lib"
+
+	"github.com/spf13/cobra"
+)
+
+// Flags for convert command
+var (
+	convertFrom   string
+	convertTo     string
+	convertInput  string
+	convertOutput string
+)
+
+// convertCmd represents the convert command
+var convertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Convert a changelog between formats",
+	Long: `Read an existing changelog and write it in another format.
+
+Markdown input may be in this tool's own format, Keep a Changelog, or a
+hand-written file with "## version" headings and bulleted entries.
+
+Formats:
+  --from   md, json
+  --to     json, markdown, keepachangelog, html, rss, atom, debian, rpm`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// The config is optional here; it only provides defaults and forge links
+		config, configErr := lib.LoadConfig(".changelogrc.yaml")
+
+		input := convertInput
+		if input == "" && configErr == nil {
+			input = config.Output.Filename
+		}
+		if input == "" {
+			fmt.Fprintln(os.Stderr, " Error: no input file; use --input")
+			os.Exit(1)
+		}
+
+		data, err := os.ReadFile(input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error reading changelog: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Labels in the configured locale are read as well as the built-in ones
+		var catalog *lib.Catalog
+		if configErr == nil {
+			catalog, err = lib.LoadCatalog(config.Output.Locale, config.Output.Catalogs)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		changelog, err := parseChangelog(string(data), convertFrom, catalog)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		var forge *lib.Forge
+		if configErr == nil {
+			if repo, err := lib.OpenRepository(config.Git.RepositoryPath); err == nil {
+				forge, _ = lib.DetectForge(repo, config)
+			}
+		}
+
+		content, err := renderChangelog(changelog, convertTo, forge, config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if convertOutput == "" {
+			fmt.Print(content)
+			return
+		}
+		if err := SaveMarkdown(content, convertOutput); err != nil {
+			fmt.Fprintf(os.Stderr, " Error saving changelog: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf(" Converted %s to %s\n", input, convertOutput)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+
+	convertCmd.Flags().StringVar(&convertFrom, "from", "md", "Input format: md or json")
+	convertCmd.Flags().StringVar(&convertTo, "to", "json", "Output format: json, markdown, keepachangelog, html, rss, atom, debian or rpm")
+	convertCmd.Flags().StringVar(&convertInput, "input", "", "Changelog to read (default: from config)")
+	convertCmd.Flags().StringVar(&convertOutput, "output", "", "File to write (default: stdout)")
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"changelog-generator/internal/lib"
+)
+
+// editorCommand returns the user's editor from $VISUAL or $EDITOR, split into
+// the program and its arguments so that editors like "code --wait" work
+func editorCommand() ([]string, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		return []string{"vi"}, nil
+	}
+	args, err := splitArgs(editor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid editor %q: %w", editor, err)
+	}
+	if len(args) == 0 {
+		return []string{"vi"}, nil
+	}
+	return args, nil
+}
+
+// splitArgs splits a command line at spaces. Single or double quotes group words,
+// so a path with spaces can be quoted. Backslashes are kept as written, since
+// they separate the directories of Windows paths.
+func splitArgs(command string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+
+	for _, r := range command {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			arg.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// editChangelog opens the changelog entries in the user's editor and reads the edits back.
+// It returns false if the user emptied the file to abort. When the edits are invalid,
+// the file is kept so they aren't lost, and its path is part of the error.
+func editChangelog(changelog *lib.Changelog) (bool, error) {
+	file, err := os.CreateTemp("", "CHANGELOG_EDIT_*.txt")
+	if err != nil {
+		return false, fmt.Errorf("failed to create edit file: %w", err)
+	}
+	path := file.Name()
+
+	_, err = file.WriteString(lib.FormatEditableChangelog(changelog))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return false, fmt.Errorf("failed to write edit file: %w", err)
+	}
+
+	// The editor runs directly rather than through a shell, which Windows doesn't have
+	command, err := editorCommand()
+	if err != nil {
+		os.Remove(path)
+		return false, err
+	}
+	editor := exec.Command(command[0], append(command[1:], path)...)
+	editor.Stdin = os.Stdin
+	editor.Stdout = os.Stderr
+	editor.Stderr = os.Stderr
+	if err := editor.Run(); err != nil {
+		os.Remove(path)
+		return false, fmt.Errorf("editor failed: %w", err)
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read edit file: %w", err)
+	}
+
+	ok, err := lib.ApplyEditedChangelog(changelog, string(edited))
+	if err != nil {
+		return false, fmt.Errorf("%w\nyour edits are kept in %s", err, path)
+	}
+	os.Remove(path)
+	return ok, nil
+}
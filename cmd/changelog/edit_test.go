@@ -0,0 +1,34 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"vim", []string{"vim"}},
+		{"code --wait", []string{"code", "--wait"}},
+		{"  nano   -w  ", []string{"nano", "-w"}},
+		{`"C:\Program Files\Notepad++\notepad++.exe" -multiInst`, []string{`C:\Program Files\Notepad++\notepad++.exe`, "-multiInst"}},
+		{`emacsclient -a '' -c`, []string{"emacsclient", "-a", "", "-c"}},
+		{`subl -n -w "--title=release notes"`, []string{"subl", "-n", "-w", "--title=release notes"}},
+	}
+	for _, tt := range tests {
+		got, err := splitArgs(tt.command)
+		if err != nil {
+			t.Errorf("splitArgs(%q) failed: %v", tt.command, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitArgs(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+
+	if _, err := splitArgs(`vim "unterminated`); err == nil {
+		t.Error("splitArgs with an unterminated quote should fail")
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Commit messages, bodies and author names come from whoever wrote the commits.
+// Every renderer passes them through the escaping for its format, so a message
+// like "Fix <script> in *all* | tables" is shown as written instead of changing
+// the document around it. JSON and the XML of the feeds are escaped by their encoders.
+
+// codeSpan matches an inline code span like `go test`
+var codeSpan = regexp.MustCompile("`[^`\n]+`")
+
+// markdownBlockStart matches text that Markdown would read as a heading, quote,
+// list or horizontal rule when it starts a line
+var markdownBlockStart = regexp.MustCompile(`^(#{1,6}(\s|$)|>|[-+](\s|$)|(-\s*){3,}$|\d+[.)](\s|$))`)
+
+// markdownTag matches the start of an HTML tag, comment or declaration
+var markdownTag = regexp.MustCompile(`<([A-Za-z/!?])`)
+
+// sanitizeText removes control characters, such as terminal escape sequences,
+// and replaces invalid UTF-8. Newlines and tabs are kept.
+func sanitizeText(text string) string {
+	text = strings.ToValidUTF8(text, "\uFFFD")
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// escapeMarkdown makes a line of text safe to put in a Markdown list item or heading.
+// With codeSpans, `code` is kept as an inline code span; otherwise the backticks
+// are escaped and shown as written.
+func escapeMarkdown(text string, codeSpans bool) string {
+	text = sanitizeText(text)
+	out := ""
+	last := 0
+	if codeSpans {
+		for _, span := range codeSpan.FindAllStringIndex(text, -1) {
+			out += escapeMarkdownInline(text[last:span[0]]) + text[span[0]:span[1]]
+			last = span[1]
+		}
+	}
+	out += escapeMarkdownInline(text[last:])
+
+	if markdownBlockStart.MatchString(out) {
+		// "# " becomes "\# ", "1. " becomes "1\. "
+		at := strings.IndexFunc(out, func(r rune) bool { return !unicode.IsDigit(r) })
+		out = out[:at] + `\` + out[at:]
+	}
+	return out
+}
+
+// escapeMarkdownInline escapes the characters that start emphasis, links, code,
+// HTML, strikethrough or table cells. Underscores inside words are left alone,
+// since they don't start emphasis.
+func escapeMarkdownInline(text string) string {
+	var out strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '\\', '`', '*', '[', ']', '<', '|', '~':
+			out.WriteByte('\\')
+		case '_':
+			if i == 0 || i == len(text)-1 || !isWordByte(text[i-1]) || !isWordByte(text[i+1]) {
+				out.WriteByte('\\')
+			}
+		}
+		out.WriteByte(text[i])
+	}
+	return out.String()
+}
+
+// isWordByte reports whether c is part of a word; bytes of non-ASCII letters count too
+func isWordByte(c byte) bool {
+	return c >= 0x80 || c == '_' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+// escapeMarkdownHTML escapes HTML tags in a line of a commit body, which is
+// otherwise written as Markdown. Code spans are left alone.
+func escapeMarkdownHTML(line string) string {
+	out := ""
+	last := 0
+	for _, span := range codeSpan.FindAllStringIndex(line, -1) {
+		out += markdownTag.ReplaceAllString(line[last:span[0]], `\<$1`) + line[span[0]:span[1]]
+		last = span[1]
+	}
+	return out + markdownTag.ReplaceAllString(line[last:], `\<$1`)
+}
+
+// bodyBreakingLine matches body lines that Markdown would read as a heading,
+// a horizontal rule or a heading underline
+var bodyBreakingLine = regexp.MustCompile(`^(\s*)(#|([-*_=])(\s*[-*_=]){2,}\s*$)`)
+
+// indentedCode matches a line of an indented code block
+var indentedCode = regexp.MustCompile(`^(    |\t)`)
+
+// escapeMarkdownBody keeps Markdown in a commit body from breaking the changelog's
+// structure or adding HTML to it. Code blocks are left alone.
+func escapeMarkdownBody(body string) string {
+	lines := strings.Split(sanitizeText(body), "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if !inFence && !indentedCode.MatchString(line) {
+			lines[i] = escapeMarkdownHTML(bodyBreakingLine.ReplaceAllString(line, `$1\$2`))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// escapeHTML makes text safe to put in HTML element content or attribute values
+func escapeHTML(text string) string {
+	return html.EscapeString(sanitizeText(text))
+}
+
+// escapeHTMLText escapes a commit message for HTML. With codeSpans, `code` is
+// rendered as a <code> element.
+func escapeHTMLText(text string, codeSpans bool) string {
+	if !codeSpans {
+		return escapeHTML(text)
+	}
+	text = sanitizeText(text)
+	out := ""
+	last := 0
+	for _, span := range codeSpan.FindAllStringIndex(text, -1) {
+		out += html.EscapeString(text[last:span[0]])
+		out += "<code>" + html.EscapeString(text[span[0]+1:span[1]-1]) + "</code>"
+		last = span[1]
+	}
+	return out + html.EscapeString(text[last:])
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"testing"
+
+	"changelog-generator/internal/lib"
+)
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		codeSpans bool
+		want      string
+	}{
+		{"plain text", "Add dark mode", false, "Add dark mode"},
+		{"html tag", "Fix <script> injection", false, `Fix \<script> injection`},
+		{"table pipe", "Split a | b", false, `Split a \| b`},
+		{"emphasis", "Make *all* requests faster", false, `Make \*all\* requests faster`},
+		{"underscore emphasis", "Keep _this_ as written", false, `Keep \_this\_ as written`},
+		{"underscore in a word", "Rename snake_case keys", false, "Rename snake_case keys"},
+		{"backticks escaped", "Run `go test` in CI", false, "Run \\`go test\\` in CI"},
+		{"backticks kept", "Run `go test` in CI", true, "Run `go test` in CI"},
+		{"code span left alone", "Document `a|b` and *c*", true, "Document `a|b` and \\*c\\*"},
+		{"unclosed backtick", "Quote ` once", true, "Quote \\` once"},
+		{"leading heading", "# of users is shown", false, `\# of users is shown`},
+		{"leading ordered list", "1. step comes first", false, `1\. step comes first`},
+		{"leading quote", "> is now an operator", false, `\> is now an operator`},
+		{"leading list marker", "- removed the flag", false, `\- removed the flag`},
+		{"hash inside text", "Fix issue #12", false, "Fix issue #12"},
+		{"control characters", "Drop \x1b[31mcolor\x1b[0m codes", false, `Drop \[31mcolor\[0m codes`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeMarkdown(tt.text, tt.codeSpans); got != tt.want {
+				t.Errorf("escapeMarkdown(%q, %v) = %q, want %q", tt.text, tt.codeSpans, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeHTMLText(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		codeSpans bool
+		want      string
+	}{
+		{"html tag", "Fix <script> injection", false, "Fix &lt;script&gt; injection"},
+		{"ampersand and quotes", `Use "a" & 'b'`, false, "Use &#34;a&#34; &amp; &#39;b&#39;"},
+		{"backticks escaped", "Run `go test`", false, "Run `go test`"},
+		{"backticks kept", "Run `<b>` tags", true, "Run <code>&lt;b&gt;</code> tags"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeHTMLText(tt.text, tt.codeSpans); got != tt.want {
+				t.Errorf("escapeHTMLText(%q, %v) = %q, want %q", tt.text, tt.codeSpans, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatEntryPullRequests(t *testing.T) {
+	config := &lib.Config{}
+	config.Forge.URL = "https://github.com/acme/tool"
+	forge, err := lib.DetectForge(nil, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		text      string
+		codeSpans bool
+		want      string
+	}{
+		{"reference", "Fix crash (#12)", false, "Fix crash ([#12](https://github.com/acme/tool/pull/12))"},
+		{"reference in code span", "Keep `git log #12` as written", true, "Keep `git log #12` as written"},
+		{"reference after code span", "Quote `x` (#7)", true, "Quote `x` ([#7](https://github.com/acme/tool/pull/7))"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := &lib.Entry{Text: tt.text}
+			if got := formatEntry(entry, forge, tt.codeSpans); got != tt.want {
+				t.Errorf("formatEntry(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownReleaseHeadingEscapesVersion(t *testing.T) {
+	release := &lib.Release{Version: "2.0.0-<beta>"}
+	got := generateMarkdownRelease(release, nil, nil, lib.EntryOptions{})
+	want := "## Version 2.0.0-\\<beta>\n"
+	if len(got) < len(want) || got[:len(want)] != want {
+		t.Errorf("heading = %q, want it to start with %q", got, want)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"changelog-generator/internal/lib"
+
+	"github.com/spf13/cobra"
+)
+
+// explainCmd represents the explain command
+var explainCmd = &cobra.Command{
+	Use:   "explain <revision>",
+	Short: "Explain how a commit is categorized",
+	Long: `Show how generate reads a commit: its parsed subject, type, scope and
+trailers, every categorization rule in the order they are tried, which rule
+decided the category, the exclusion rules, and the changelog line the commit
+becomes.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := lib.LoadConfig(".changelogrc.yaml")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error loading config: %v\n", err)
+			fmt.Fprintln(os.Stderr, " Tip: Run 'changelog init' to create a config file")
+			os.Exit(1)
+		}
+
+		repo, err := lib.OpenRepository(config.Git.RepositoryPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error opening repository: %v\n", err)
+			os.Exit(1)
+		}
+
+		classifier, err := lib.NewClassifier(repo, config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error in categorization config: %v\n", err)
+			os.Exit(1)
+		}
+
+		commit, err := lib.GetCommit(repo, args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		forge, _ := lib.DetectForge(repo, config)
+		explainCommit(os.Stdout, commit, classifier, forge, config)
+	},
+}
+
+// explainCommit prints the parsed commit, the rule results and the resulting entry
+func explainCommit(w io.Writer, commit *lib.Commit, classifier *lib.Classifier, forge *lib.Forge, config *lib.Config) {
+	header := classifier.Parse(commit.Message)
+	fmt.Fprintf(w, "Commit %s\n", commit.FullHash)
+	fmt.Fprintf(w, "Author: %s <%s>\n", commit.Author, commit.Email)
+	fmt.Fprintf(w, "Date:   %s\n", commit.Date.Format("2006-01-02 15:04:05"))
+	fmt.Fprintln(w)
+
+	if header.Parser != "" {
+		fmt.Fprintf(w, "Parsed by %s:\n", header.Parser)
+	} else {
+		fmt.Fprintln(w, "Parsed:")
+	}
+	fmt.Fprintf(w, "  Subject:  %s\n", header.Subject)
+	fmt.Fprintf(w, "  Type:     %s\n", orNone(header.Type))
+	fmt.Fprintf(w, "  Scope:    %s\n", orNone(header.Scope))
+	fmt.Fprintf(w, "  Breaking: %v\n", header.Breaking)
+	if header.Ticket != "" {
+		fmt.Fprintf(w, "  Ticket:   %s\n", header.Ticket)
+	}
+	if header.Gitmoji != "" {
+		fmt.Fprintf(w, "  Gitmoji:  %s\n", header.Gitmoji)
+	}
+	trailers := lib.ParseTrailers(commit.Message)
+	if len(trailers) == 0 {
+		fmt.Fprintln(w, "  Trailers: (none)")
+	}
+	for i, trailer := range trailers {
+		label := "         "
+		if i == 0 {
+			label = "Trailers:"
+		}
+		fmt.Fprintf(w, "  %s %s: %s\n", label, trailer.Key, trailer.Value)
+	}
+	if body := lib.CommitBody(commit.Message); body != "" {
+		fmt.Fprintf(w, "  Body:     %d lines\n", strings.Count(body, "\n")+1)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "Rules, in order (the first match wins):")
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	decided := false
+	var category lib.CommitCategory
+	for _, result := range classifier.Explain(commit) {
+		mark, outcome := "✗", ""
+		if result.Matched {
+			outcome = " → " + strings.TrimSpace(string(result.Category))
+			if decided {
+				mark, outcome = "-", outcome+" (an earlier rule matched)"
+			} else {
+				mark = "✓"
+				decided = true
+				category = result.Category
+			}
+		}
+		fmt.Fprintf(table, "  %s\t%s\t%s%s\n", mark, result.Rule, result.Detail, outcome)
+	}
+	table.Flush()
+	fmt.Fprintln(w)
+
+	excludedBy := ""
+	exclusions := classifier.ExplainExclusions(commit)
+	if len(exclusions) == 0 {
+		fmt.Fprintln(w, "Exclusions: none configured")
+	} else {
+		fmt.Fprintln(w, "Exclusions (any match leaves the commit out):")
+		table = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, result := range exclusions {
+			mark := "✗"
+			if result.Matched {
+				mark = "✓"
+				if excludedBy == "" {
+					excludedBy = result.Rule
+				}
+			}
+			fmt.Fprintf(table, "  %s\t%s\t%s\n", mark, result.Rule, result.Detail)
+		}
+		table.Flush()
+	}
+	fmt.Fprintln(w)
+
+	release := lib.BuildRelease([]*lib.Commit{commit}, "", commit.Date, classifier)
+	fmt.Fprintf(w, "Category: %s\n", strings.TrimSpace(string(category)))
+	if excludedBy != "" {
+		fmt.Fprintf(w, "Entry:    (excluded by %s)\n", excludedBy)
+	}
+	for _, section := range release.Sections {
+		for _, entry := range section.Entries {
+			fmt.Fprintf(w, "Entry:    - %s\n", formatEntry(entry, forge, config.Output.Entries.CodeSpans))
+		}
+	}
+}
+
+// orNone shows an empty field as "(none)"
+func orNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"changelog-generator/internal/lib"
+)
+
+// rssFeed is an RSS 2.0 document
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate,omitempty"`
+	Description string  `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// atomFeed is an Atom 1.0 document
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    *atomLink   `xml:"link,omitempty"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    *atomLink   `xml:"link,omitempty"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// GenerateRSS creates an RSS 2.0 feed with one item per release. The channel needs
+// a link: output.feed.link, or the forge's project page.
+func GenerateRSS(changelog *lib.Changelog, forge *lib.Forge, catalog *lib.Catalog, entries lib.EntryOptions, options lib.FeedOptions) (string, error) {
+	channel := rssChannel{
+		Title:       feedTitle(changelog, options),
+		Link:        feedLink(forge, options),
+		Description: options.Description,
+	}
+	if channel.Link == "" {
+		return "", fmt.Errorf("rss needs a channel link: set output.feed.link")
+	}
+	if channel.Description == "" {
+		channel.Description = channel.Title
+	}
+
+	for _, release := range feedReleases(changelog) {
+		item := rssItem{
+			Title:       catalog.T("version") + " " + release.Version,
+			Link:        feedReleaseLink(release, forge, options),
+			GUID:        rssGUID{IsPermaLink: "false", Value: feedReleaseID(changelog, release)},
+			Description: generateHTMLSections(release, forge, catalog, entries),
+		}
+		if !release.Date.IsZero() {
+			item.PubDate = release.Date.Format(time.RFC1123Z)
+			if channel.LastBuildDate == "" {
+				channel.LastBuildDate = item.PubDate
+			}
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	return encodeFeed(rssFeed{Version: "2.0", Channel: channel})
+}
+
+// GenerateAtom creates an Atom 1.0 feed with one entry per release
+func GenerateAtom(changelog *lib.Changelog, forge *lib.Forge, catalog *lib.Catalog, entries lib.EntryOptions, options lib.FeedOptions) (string, error) {
+	feed := atomFeed{
+		Title:  feedTitle(changelog, options),
+		ID:     feedID(changelog),
+		Author: atomAuthor{Name: changelog.Title},
+	}
+	if feed.Author.Name == "" {
+		feed.Author.Name = feed.Title
+	}
+	if link := feedLink(forge, options); link != "" {
+		feed.Link = &atomLink{Href: link}
+	}
+
+	// Undated releases, e.g. from a parsed changelog, get the newest known date
+	releases := feedReleases(changelog)
+	newest := time.Time{}
+	for _, release := range releases {
+		if release.Date.After(newest) {
+			newest = release.Date
+		}
+	}
+	if newest.IsZero() {
+		newest = time.Now()
+	}
+	feed.Updated = newest.UTC().Format(time.RFC3339)
+
+	for _, release := range releases {
+		date := release.Date
+		if date.IsZero() {
+			date = newest
+		}
+		entry := atomEntry{
+			Title:   catalog.T("version") + " " + release.Version,
+			ID:      feedReleaseID(changelog, release),
+			Updated: date.UTC().Format(time.RFC3339),
+			Content: atomContent{Type: "html", Value: generateHTMLSections(release, forge, catalog, entries)},
+		}
+		if link := feedReleaseLink(release, forge, options); link != "" {
+			entry.Link = &atomLink{Href: link}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return encodeFeed(feed)
+}
+
+// encodeFeed writes a feed document with the XML declaration
+func encodeFeed(feed interface{}) (string, error) {
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode feed: %w", err)
+	}
+	return xml.Header + string(data) + "\n", nil
+}
+
+// feedReleases returns the released versions; unreleased changes have no place in a feed
+func feedReleases(changelog *lib.Changelog) []*lib.Release {
+	var releases []*lib.Release
+	for _, release := range changelog.Releases {
+		if !release.IsUnreleased() {
+			releases = append(releases, release)
+		}
+	}
+	return releases
+}
+
+// feedTitle is the configured feed title, or "<project> releases"
+func feedTitle(changelog *lib.Changelog, options lib.FeedOptions) string {
+	if options.Title != "" {
+		return options.Title
+	}
+	if changelog.Title != "" {
+		return changelog.Title + " releases"
+	}
+	return "Releases"
+}
+
+// feedIDUnsafe matches characters that are left out of feed IDs
+var feedIDUnsafe = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// feedID identifies the feed, e.g. "urn:changelog:my-project"
+func feedID(changelog *lib.Changelog) string {
+	name := strings.Trim(feedIDUnsafe.ReplaceAllString(strings.ToLower(changelog.Title), "-"), "-")
+	if name == "" {
+		name = "project"
+	}
+	return "urn:changelog:" + name
+}
+
+// feedReleaseID identifies a release; it only depends on the version, so it
+// stays the same each time the feed is regenerated
+func feedReleaseID(changelog *lib.Changelog, release *lib.Release) string {
+	return feedID(changelog) + ":" + release.Version
+}
+
+// feedLink is the configured feed link, or the forge's project page
+func feedLink(forge *lib.Forge, options lib.FeedOptions) string {
+	if options.Link != "" {
+		return options.Link
+	}
+	if forge != nil {
+		return forge.BaseURL
+	}
+	return ""
+}
+
+// feedReleaseLink points to the release's anchor in the hosted changelog,
+// or to its tag on the forge
+func feedReleaseLink(release *lib.Release, forge *lib.Forge, options lib.FeedOptions) string {
+	if options.Link != "" {
+		return options.Link + "#" + releaseAnchor(release)
+	}
+	if release.Tag == "" {
+		return ""
+	}
+	return forge.TagURL(release.Tag)
+}
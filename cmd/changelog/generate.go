@@ -2,10 +2,15 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
 
 	"changelog-generator/internal/lib"
 
+	"github.com/go-git/go-git/v5"
 	"github.com/spf13/cobra"
 )
 
@@ -16,6 +21,17 @@ var (
 	commitCount   int
 	outputFile    string
 	useAI         bool
+
+	generateVersion string
+	generateFormat  string
+	generateAll     bool
+	generateStdout  bool
+	generateDryRun  bool
+
+	generateInteractive bool
+	generateEdit        bool
+
+	generateShowExcluded bool
 )
 
 // generateCmd represents the generate command
@@ -30,65 +46,146 @@ This command will:
   3. Find commits between the specified range
   4. Generate a beautiful changelog`,
 	Run: func(cmd *cobra.Command, args []string) {
+		// With --stdout and --dry-run, stdout only carries the result;
+		// progress messages go to stderr instead
+		var progress io.Writer = os.Stdout
+		quiet := generateStdout || generateDryRun
+		if quiet {
+			progress = os.Stderr
+		}
+
 		// Load configuration
 		config, err := lib.LoadConfig(".changelogrc.yaml")
 		if err != nil {
-			fmt.Printf(" Error loading config: %v\n", err)
-			fmt.Println()
-			fmt.Println(" Tip: Run 'changelog init' to create a config file")
+			fmt.Fprintf(progress, " Error loading config: %v\n", err)
+			fmt.Fprintln(progress)
+			fmt.Fprintln(progress, " Tip: Run 'changelog init' to create a config file")
 			os.Exit(1)
 		}
 
-		fmt.Println(" Generating changelog...")
-		fmt.Println()
-		fmt.Printf(" Project: %s\n", config.Project.Name)
-		fmt.Printf("Repository: %s\n", config.Git.RepositoryPath)
-		fmt.Println()
+		fmt.Fprintln(progress, " Generating changelog...")
+		fmt.Fprintln(progress)
+		fmt.Fprintf(progress, " Project: %s\n", config.Project.Name)
+		fmt.Fprintf(progress, "Repository: %s\n", config.Git.RepositoryPath)
+		fmt.Fprintln(progress)
 
 		// Open the git repository
 		repo, err := lib.OpenRepository(config.Git.RepositoryPath)
 		if err != nil {
-			fmt.Printf(" Error opening repository: %v\n", err)
-			fmt.Println()
-			fmt.Println(" Make sure you're in a git repository!")
+			fmt.Fprintf(progress, " Error opening repository: %v\n", err)
+			fmt.Fprintln(progress)
+			fmt.Fprintln(progress, " Make sure you're in a git repository!")
+			os.Exit(1)
+		}
+
+		classifier, err := lib.NewClassifier(repo, config)
+		if err != nil {
+			fmt.Fprintf(progress, " Error in categorization config: %v\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Printf(" Opened repository at: %s\n", config.Git.RepositoryPath)
-		fmt.Println()
+		fmt.Fprintf(progress, " Opened repository at: %s\n", config.Git.RepositoryPath)
+		fmt.Fprintln(progress)
 
-		// Get recent commits
-		fmt.Printf(" Fetching last %d commits...\n", commitCount)
-		commits, err := lib.GetRecentCommits(repo, commitCount)
+		// Work out where the repository is hosted so entries can link back to it
+		forge, err := lib.DetectForge(repo, config)
 		if err != nil {
-			fmt.Printf(" Error getting commits: %v\n", err)
+			fmt.Fprintf(progress, " Links disabled: %v\n", err)
+			fmt.Fprintln(progress)
+		}
+
+		// Work out which versions and commits go into the changelog
+		ranges, err := loadReleaseRanges(cmd, repo, config, progress)
+		if err != nil {
+			fmt.Fprintf(progress, " Error getting commits: %v\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Printf(" Found %d commits\n", len(commits))
-		fmt.Println()
+		// Leave out the commits matched by the exclusion rules before anything else sees them
+		var commits []*lib.Commit
+		var excluded []lib.ExcludedCommit
+		for _, r := range ranges {
+			var left []lib.ExcludedCommit
+			r.Commits, left = classifier.Exclude(r.Commits)
+			commits = append(commits, r.Commits...)
+			excluded = append(excluded, left...)
+		}
+
+		fmt.Fprintf(progress, " Found %d commits\n", len(commits)+len(excluded))
+		fmt.Fprintln(progress)
+		reportExcluded(progress, excluded, generateShowExcluded)
 
 		//AI processing
 		if useAI {
 			aiClient, err := lib.NewAIClient()
 			if err != nil {
-				fmt.Printf(" AI not available: %v\n", err)
-				fmt.Println("   Continuing without AI enhancement...")
-				fmt.Println()
+				fmt.Fprintf(progress, " AI not available: %v\n", err)
+				fmt.Fprintln(progress, "   Continuing without AI enhancement...")
+				fmt.Fprintln(progress)
 			} else {
-				aiClient.ImproveAllCommits(commits)
+				aiClient.ImproveAllCommits(commits, progress)
 			}
 		}
 
-		// Categorize and group commits
-		groups := lib.GroupCommitsByCategory(commits)
-
 		// Display grouped commits
-		lib.PrintGroupedCommits(groups)
+		if !quiet {
+			lib.PrintGroupedCommits(classifier.Group(commits))
+		}
 
-		// Generate markdown
-		fmt.Println(" Generating markdown...")
-		markdown := GenerateMarkdown(commits, config.Project.Name, config.Project.Version)
+		// Build the releases
+		changelog := &lib.Changelog{Title: config.Project.Name}
+		for _, r := range ranges {
+			release := r.Build(classifier)
+			if config.Contributors.Enabled {
+				release.Contributors, err = collectContributors(repo, r.Commits, config)
+				if err != nil {
+					fmt.Fprintf(progress, " Error collecting contributors: %v\n", err)
+					os.Exit(1)
+				}
+			}
+			changelog.Releases = append(changelog.Releases, release)
+		}
+
+		// Let the user fix up the entries before anything is written
+		if generateInteractive {
+			ok, err := reviewChangelog(changelog, os.Stdin, progress)
+			if err != nil {
+				fmt.Fprintf(progress, " Error: %v\n", err)
+				os.Exit(1)
+			}
+			if !ok {
+				fmt.Fprintln(progress, " Review cancelled, nothing written")
+				return
+			}
+		}
+		if generateEdit {
+			ok, err := editChangelog(changelog)
+			if err != nil {
+				fmt.Fprintf(progress, " Error in edited changelog:\n%v\n", err)
+				os.Exit(1)
+			}
+			if !ok {
+				fmt.Fprintln(progress, " Edit file was emptied, nothing written")
+				return
+			}
+		}
+
+		// Render the changelog
+		format := generateFormat
+		if format == "" {
+			format = config.Output.Format
+		}
+		fmt.Fprintf(progress, " Generating %s...\n", format)
+		markdown, err := renderChangelog(changelog, format, forge, config)
+		if err != nil {
+			fmt.Fprintf(progress, " Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if generateStdout {
+			fmt.Fprint(os.Stdout, markdown)
+			return
+		}
 
 		// Determine output filename
 		filename := outputFile
@@ -96,19 +193,139 @@ This command will:
 			filename = config.Output.Filename
 		}
 
+		// Compare with the file on disk instead of writing it
+		if generateDryRun {
+			existing, err := os.ReadFile(filename)
+			if err != nil && !os.IsNotExist(err) {
+				fmt.Fprintf(progress, " Error reading %s: %v\n", filename, err)
+				os.Exit(1)
+			}
+			diff := lib.UnifiedDiff("a/"+filename, "b/"+filename, string(existing), markdown)
+			if diff == "" {
+				fmt.Fprintf(progress, " %s is up to date\n", filename)
+				return
+			}
+			fmt.Fprint(os.Stdout, diff)
+			os.Exit(1)
+		}
+
 		// Save to file
 		err = SaveMarkdown(markdown, filename)
 		if err != nil {
-			fmt.Printf(" Error saving file: %v\n", err)
+			fmt.Fprintf(progress, " Error saving file: %v\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Printf("Changelog saved to: %s\n", filename)
-		fmt.Println()
-		fmt.Println(" Done!")
+		fmt.Fprintf(progress, "Changelog saved to: %s\n", filename)
+		fmt.Fprintln(progress)
+		fmt.Fprintln(progress, " Done!")
 	},
 }
 
+// loadReleaseRanges picks the releases and commits for the changelog. --all covers every
+// version tag; otherwise a single release is built where an explicit --since/--to range
+// wins, then the range implied by the version, then the last --count commits.
+func loadReleaseRanges(cmd *cobra.Command, repo *git.Repository, config *lib.Config, progress io.Writer) ([]*lib.ReleaseRange, error) {
+	if generateAll {
+		fmt.Fprintln(progress, " Fetching every release...")
+		return lib.ListReleaseRanges(repo, config)
+	}
+
+	// Work out which version this changelog is for
+	resolved, err := lib.ResolveVersion(repo, config, generateVersion)
+	if err != nil {
+		return nil, err
+	}
+	if resolved.Version == "" {
+		fmt.Fprintf(progress, " Version: Unreleased (no %s<version> tags yet)\n", config.Versioning.TagPrefix)
+	} else {
+		fmt.Fprintf(progress, " Version: %s (from %s)\n", resolved.Version, resolved.Strategy)
+	}
+
+	r := &lib.ReleaseRange{Version: resolved.Version, Tag: resolved.Tag, Date: resolved.Date}
+	if resolved.PreviousTag != nil {
+		r.PreviousTag = resolved.PreviousTag.Name
+	}
+
+	switch {
+	case cmd.Flags().Changed("since") || cmd.Flags().Changed("to"):
+		fmt.Fprintf(progress, " Fetching commits %s..%s...\n", generateSince, generateTo)
+		from, err := lib.ResolveRevision(repo, generateSince)
+		if err != nil {
+			return nil, err
+		}
+		to, err := lib.ResolveRevision(repo, generateTo)
+		if err != nil {
+			return nil, err
+		}
+		r.Commits, err = lib.GetCommitsBetween(repo, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+	case resolved.HasRange():
+		previous := "the beginning"
+		if resolved.PreviousTag != nil {
+			previous = resolved.PreviousTag.Name
+		}
+		fmt.Fprintf(progress, " Fetching commits since %s...\n", previous)
+		r.Commits, err = lib.GetCommitsBetween(repo, resolved.From, resolved.To)
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		fmt.Fprintf(progress, " Fetching last %d commits...\n", commitCount)
+		r.Commits, err = lib.GetRecentCommits(repo, commitCount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return []*lib.ReleaseRange{r}, nil
+}
+
+// reportExcluded says how many commits the exclusion rules left out and, when
+// list is set, which ones and why
+func reportExcluded(w io.Writer, excluded []lib.ExcludedCommit, list bool) {
+	if len(excluded) == 0 {
+		return
+	}
+	if !list {
+		fmt.Fprintf(w, " Excluded %d commits (--show-excluded lists them)\n", len(excluded))
+		fmt.Fprintln(w)
+		return
+	}
+
+	fmt.Fprintf(w, " Excluded %d commits:\n", len(excluded))
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, e := range excluded {
+		subject := strings.SplitN(e.Commit.Message, "\n", 2)[0]
+		fmt.Fprintf(table, "   %s\t%s\t(%s)\n", e.Commit.Hash, subject, e.Rule)
+	}
+	table.Flush()
+	fmt.Fprintln(w)
+}
+
+// collectContributors gathers contributors using the mailmap and exclusions from config
+func collectContributors(repo *git.Repository, commits []*lib.Commit, config *lib.Config) ([]*lib.Contributor, error) {
+	mailmapPath := config.Contributors.Mailmap
+	if mailmapPath == "" {
+		mailmapPath = ".mailmap"
+	}
+
+	mailmap, err := lib.LoadMailmap(filepath.Join(config.Git.RepositoryPath, mailmapPath))
+	if err != nil {
+		return nil, err
+	}
+
+	return lib.CollectContributors(repo, commits, lib.ContributorOptions{
+		Mailmap:   mailmap,
+		Exclude:   config.Contributors.Exclude,
+		FirstTime: config.Contributors.FirstTime,
+	})
+}
+
 func init() {
 	// Add generate command to root command
 	rootCmd.AddCommand(generateCmd)
@@ -119,5 +336,15 @@ func init() {
 	generateCmd.Flags().IntVar(&commitCount, "count", 10, "Number of commits to show")
 	generateCmd.Flags().StringVar(&outputFile, "output", "", "Output file (default from config)")
 	generateCmd.Flags().BoolVar(&useAI, "ai", false, "Use AI to improve commit messages")
+	generateCmd.Flags().StringVar(&generateFormat, "format", "", "Output format: markdown, keepachangelog, html, rss, atom, debian, rpm or json (default from config)")
+	generateCmd.Flags().BoolVar(&generateAll, "all", false, "Include every version tag, plus unreleased changes")
+	generateCmd.Flags().BoolVar(&generateStdout, "stdout", false, "Print only the changelog to stdout instead of writing the file")
+	generateCmd.Flags().BoolVar(&generateDryRun, "dry-run", false, "Show a diff against the existing file and exit 1 if it would change")
+	generateCmd.MarkFlagsMutuallyExclusive("stdout", "dry-run")
+	generateCmd.Flags().BoolVar(&generateInteractive, "interactive", false, "Review, recategorize and reword entries before saving")
+	generateCmd.Flags().BoolVar(&generateEdit, "edit", false, "Edit the entries in $VISUAL or $EDITOR before saving")
+	generateCmd.MarkFlagsMutuallyExclusive("interactive", "edit")
+	generateCmd.Flags().StringVar(&generateVersion, "version", "", "Version for the changelog (overrides versioning.strategy)")
+	generateCmd.Flags().BoolVar(&generateShowExcluded, "show-excluded", false, "List the commits left out by the exclude rules, and the rule for each")
 
 }
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"changelog-generator/internal/lib"
+)
+
+// defaultHTMLStyle is the built-in theme; it follows the reader's light or dark preference
+const defaultHTMLStyle = `:root {
+  --bg: #ffffff; --fg: #1f2328; --muted: #656d76; --border: #d0d7de; --link: #0969da;
+  --breaking: #cf222e; --features: #1a7f37; --fixes: #9a6700; --performance: #8250df;
+  --refactoring: #0550ae; --documentation: #57606a; --tests: #57606a; --chores: #57606a; --other: #57606a;
+}
+@media (prefers-color-scheme: dark) {
+  :root {
+    --bg: #0d1117; --fg: #e6edf3; --muted: #8d96a0; --border: #30363d; --link: #4493f8;
+    --breaking: #f85149; --features: #3fb950; --fixes: #d29922; --performance: #a371f7;
+    --refactoring: #79c0ff; --documentation: #8d96a0; --tests: #8d96a0; --chores: #8d96a0; --other: #8d96a0;
+  }
+}
+body { margin: 0; background: var(--bg); color: var(--fg); font: 16px/1.5 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; }
+.changelog { max-width: 48rem; margin: 0 auto; padding: 2rem 1rem; }
+.changelog a { color: var(--link); }
+.changelog .release { border-top: 1px solid var(--border); padding-top: 1rem; }
+.changelog h2 a { color: inherit; text-decoration: none; }
+.changelog .release-meta { color: var(--muted); font-size: 0.875rem; }
+.changelog .badge { display: inline-block; padding: 0.1rem 0.6rem; border-radius: 1rem; color: #fff; font-size: 0.875rem; }
+.changelog .badge-breaking { background: var(--breaking); }
+.changelog .badge-features { background: var(--features); }
+.changelog .badge-fixes { background: var(--fixes); }
+.changelog .badge-performance { background: var(--performance); }
+.changelog .badge-refactoring { background: var(--refactoring); }
+.changelog .badge-documentation { background: var(--documentation); }
+.changelog .badge-tests { background: var(--tests); }
+.changelog .badge-chores { background: var(--chores); }
+.changelog .badge-other { background: var(--other); }
+.changelog .commit { font-family: ui-monospace, monospace; font-size: 0.875rem; }
+.changelog .entry-body { white-space: pre-wrap; color: var(--muted); margin: 0.25rem 0 0.5rem; }
+.changelog .first-time { color: var(--muted); font-style: italic; }
+.changelog footer { color: var(--muted); border-top: 1px solid var(--border); padding-top: 1rem; }
+`
+
+// GenerateHTML renders the changelog as a standalone HTML page, or as a fragment for embedding.
+// forge may be nil, in which case commit hashes are not linked.
+func GenerateHTML(changelog *lib.Changelog, forge *lib.Forge, catalog *lib.Catalog, entries lib.EntryOptions, options lib.HTMLOptions) (string, error) {
+	body := `<div class="changelog">` + "\n"
+	body += fmt.Sprintf("<h1>%s</h1>\n", escapeHTML(htmlTitle(changelog, catalog)))
+	for _, release := range changelog.Releases {
+		body += generateHTMLRelease(release, forge, catalog, entries)
+	}
+	body += fmt.Sprintf("<footer>%s: %d</footer>\n", escapeHTML(catalog.T("total_commits")), changelog.EntryCount())
+	body += "</div>\n"
+
+	if options.Fragment {
+		return body, nil
+	}
+
+	style, err := htmlStylesheet(options.Stylesheet)
+	if err != nil {
+		return "", err
+	}
+
+	page := "<!DOCTYPE html>\n"
+	page += fmt.Sprintf(`<html lang="%s">`+"\n<head>\n", escapeHTML(catalog.Language()))
+	page += `<meta charset="utf-8">` + "\n"
+	page += `<meta name="viewport" content="width=device-width, initial-scale=1">` + "\n"
+	page += fmt.Sprintf("<title>%s</title>\n", escapeHTML(htmlTitle(changelog, catalog)))
+	page += style
+	page += "</head>\n<body>\n" + body + "</body>\n</html>\n"
+	return page, nil
+}
+
+// htmlTitle is the page title, matching the Markdown heading
+func htmlTitle(changelog *lib.Changelog, catalog *lib.Catalog) string {
+	if changelog.Title == "" {
+		return catalog.T("changelog")
+	}
+	return catalog.T("changelog") + " - " + changelog.Title
+}
+
+// htmlStylesheet returns the style element for the page: the built-in theme,
+// a configured CSS file inlined, or a link to a configured URL
+func htmlStylesheet(stylesheet string) (string, error) {
+	switch {
+	case stylesheet == "":
+		return "<style>\n" + defaultHTMLStyle + "</style>\n", nil
+	case strings.HasPrefix(stylesheet, "http://") || strings.HasPrefix(stylesheet, "https://"):
+		return fmt.Sprintf(`<link rel="stylesheet" href="%s">`+"\n", escapeHTML(stylesheet)), nil
+	}
+
+	css, err := os.ReadFile(stylesheet)
+	if err != nil {
+		return "", fmt.Errorf("failed to read stylesheet: %w", err)
+	}
+	return "<style>\n" + string(css) + "</style>\n", nil
+}
+
+// generateHTMLRelease renders one version section with an anchor to link to
+func generateHTMLRelease(release *lib.Release, forge *lib.Forge, catalog *lib.Catalog, entries lib.EntryOptions) string {
+	id := releaseAnchor(release)
+	heading := catalog.T("version") + " " + release.Version
+	if release.IsUnreleased() {
+		heading = catalog.T("unreleased")
+	}
+
+	out := fmt.Sprintf(`<section class="release" id="%s">`+"\n", id)
+	out += fmt.Sprintf(`<h2><a href="#%s">%s</a></h2>`+"\n", id, escapeHTML(heading))
+
+	var meta []string
+	if !release.Date.IsZero() {
+		meta = append(meta, fmt.Sprintf(`<time datetime="%s">%s</time>`,
+			release.Date.Format("2006-01-02"), escapeHTML(catalog.FormatDate(release.Date))))
+	}
+	if release.PreviousTag != "" {
+		to := release.Tag
+		if to == "" {
+			to = "HEAD" // not tagged yet
+		}
+		label := escapeHTML(release.PreviousTag + "..." + to)
+		if url := forge.CompareURL(release.PreviousTag, to); url != "" {
+			label = fmt.Sprintf(`<a href="%s">%s</a>`, escapeHTML(url), label)
+		}
+		meta = append(meta, label)
+	}
+	if len(meta) > 0 {
+		out += fmt.Sprintf(`<p class="release-meta">%s</p>`+"\n", strings.Join(meta, " · "))
+	}
+
+	out += generateHTMLSections(release, forge, catalog, entries)
+	return out + "</section>\n"
+}
+
+// generateHTMLSections renders the categories and contributors of a release
+func generateHTMLSections(release *lib.Release, forge *lib.Forge, catalog *lib.Catalog, entries lib.EntryOptions) string {
+	out := ""
+	for _, section := range release.Sections {
+		title := section.Title
+		if title == "" {
+			title = catalog.Category(section.Category)
+		}
+		if entries.GitmojiHeadings {
+			title = gitmojiTitle(section.Category, title)
+		}
+		out += fmt.Sprintf(`<h3><span class="badge badge-%s">%s</span></h3>`+"\n",
+			section.Category.Key(), escapeHTML(title))
+
+		style := entries.Body.Style(section.Category)
+		out += "<ul>\n"
+		for _, entry := range section.Entries {
+			out += fmt.Sprintf("<li>%s%s</li>\n", formatHTMLEntry(entry, forge, entries.CodeSpans), htmlEntryBody(entry.Body, style, catalog))
+		}
+		out += "</ul>\n"
+	}
+
+	return out + generateHTMLContributors(release.Contributors, catalog)
+}
+
+// generateHTMLContributors renders the contributors list of a release
+func generateHTMLContributors(contributors []*lib.Contributor, catalog *lib.Catalog) string {
+	if len(contributors) == 0 {
+		return ""
+	}
+
+	out := fmt.Sprintf("<h3>%s</h3>\n", escapeHTML(catalog.T("contributors")))
+	out += `<ul class="contributors">` + "\n"
+	for _, contributor := range contributors {
+		out += "<li>" + escapeHTML(contributor.Name)
+		if contributor.FirstTime {
+			out += fmt.Sprintf(` <span class="first-time">(%s)</span>`, escapeHTML(catalog.T("first_contribution")))
+		}
+		out += "</li>\n"
+	}
+	return out + "</ul>\n"
+}
+
+// htmlEntryBody renders the body of an entry; the text is shown as written
+func htmlEntryBody(body, style string, catalog *lib.Catalog) string {
+	if body == "" || style == "" || style == lib.BodyNone {
+		return ""
+	}
+
+	out := fmt.Sprintf(`<div class="entry-body">%s</div>`, escapeHTML(body))
+	if style == lib.BodyDetails {
+		return fmt.Sprintf("<details><summary>%s</summary>%s</details>", escapeHTML(catalog.T("details")), out)
+	}
+	return out
+}
+
+// formatHTMLEntry renders the text of an entry followed by its commit link, if it has one
+func formatHTMLEntry(entry *lib.Entry, forge *lib.Forge, codeSpans bool) string {
+	text := escapeHTMLText(entry.Text, codeSpans)
+	if url := forge.PullRequestURL("1"); url != "" {
+		text = pullRequestRef.ReplaceAllStringFunc(text, func(match string) string {
+			parts := pullRequestRef.FindStringSubmatch(match)
+			return fmt.Sprintf(`%s<a href="%s">#%s</a>`, parts[1], escapeHTML(forge.PullRequestURL(parts[2])), parts[2])
+		})
+	}
+	if entry.Hash == "" {
+		return text
+	}
+
+	hash := entry.FullHash
+	if hash == "" {
+		hash = entry.Hash
+	}
+	if url := forge.CommitURL(hash); url != "" {
+		return fmt.Sprintf(`%s <a class="commit" href="%s">%s</a>`, text, escapeHTML(url), escapeHTML(entry.Hash))
+	}
+	return fmt.Sprintf(`%s <code class="commit">%s</code>`, text, escapeHTML(entry.Hash))
+}
+
+// anchorUnsafe matches characters that are left out of anchor IDs
+var anchorUnsafe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// releaseAnchor returns the anchor ID of a release, e.g. "v1-2-0" or "unreleased"
+func releaseAnchor(release *lib.Release) string {
+	if release.IsUnreleased() {
+		return "unreleased"
+	}
+	return "v" + strings.Trim(anchorUnsafe.ReplaceAllString(strings.ToLower(release.Version), "-"), "-")
+}
@@ -62,17 +62,133 @@ project:
 git:
   repository_path: "."
   default_branch: "main"
+  remote: "origin"
+
+# Links to commits, tags and pull requests
+# The forge is detected from the remote URL (github, gitlab, bitbucket, gitea).
+# For self-hosted instances, map the host or provide custom URL patterns.
+forge:
+  type: ""
+  # hosts:
+  #   git.example.com: gitlab
+  # commit_url: "{base}/commit/{hash}"
+  # compare_url: "{base}/compare/{from}...{to}"
+  # tag_url: "{base}/releases/tag/{tag}"
+  # pull_request_url: "{base}/pull/{number}"
+
+# Where the changelog version comes from:
+#   tag    - latest version tag and the commits leading up to it
+#   next   - next version calculated from the commits since the last release
+#   config - project.version above
+# "changelog generate --version X" always wins.
+versioning:
+  strategy: "tag"
+  tag_prefix: "v"   # e.g. "api/v" for monorepo tags like api/v1.2.0
+  scheme: "semver"  # semver or calver
+  # calver_format: "YYYY.0M.MICRO"
+  # Pre-release channels in release order (--pre beta gives 1.3.0-beta.1)
+  channels: [alpha, beta, rc]
 
 # Output settings
 output:
-  format: "markdown"
+  format: "markdown"     # or "keepachangelog", "html", "rss", "atom", "debian", "rpm", "json"
   filename: "CHANGELOG.md"
+  # locale: "de"                    # en, de, fr, es, or a custom catalog
+  # catalogs: ".changelog/locales"  # directory with custom <locale>.yaml files
+  # body:                           # commit bodies: none, indented or details
+  #   features: "indented"
+  #   default: "none"
+  # code_spans: true                # keep inline code in commit messages instead of escaping it
+  # gitmoji_headings: true          # start category headings with their gitmoji, e.g. "✨ Features"
+  # keepachangelog:                 # Keep a Changelog section per commit type or category; "" leaves it out
+  #   docs: "Changed"
+  # html:
+  #   fragment: true            # only the changelog markup, for embedding
+  #   stylesheet: "theme.css"   # CSS file or URL replacing the built-in theme
+  # feed:
+  #   link: "https://example.com/changelog.html"   # items link to version anchors; default: the forge project page
+
+# Package changelogs for the debian and rpm formats
+# packaging:
+#   maintainer: "Jane Doe <jane@example.com>"
+#   distribution: "unstable"
+#   urgency: "medium"
 
 # AI settings (for future use)
 ai:
   enabled: false
   provider: "claude"
 
+# Contributors section at the end of each release
+contributors:
+  enabled: false
+  mailmap: ".mailmap"
+  highlight_first_time: true
+  # Regular expressions matched against "Name <email>"
+  exclude:
+    - "\\[bot\\]"
+    - "^dependabot"
+    - "^renovate"
+
+# Release command settings
+release:
+  commit_message: "chore(release): {tag}"
+
+# Files that hold the version, updated by "changelog release" and "changelog bump"
+# Each target must match exactly once, otherwise no file is changed.
+version_targets: []
+#  - path: VERSION
+#    pattern: '^(\S+)$'
+#  - path: package.json
+#    json_path: version
+#  - path: charts/app/Chart.yaml
+#    yaml_path: appVersion
+
+# Custom commit formats: regular expressions with the named groups type, scope,
+# subject, ticket and breaking, tried in order before the conventional format
+parsers: []
+#  - name: jira
+#    pattern: '^(?P<ticket>[A-Z]+-\d+) \| (?P<type>\w+) \| (?P<subject>.+)$'
+# Categories for the types captured by the parsers
+type_categories: {}
+#  BUGFIX: fixes
+
+# Gitmoji (":sparkles:" or "✨" at the start of a subject) are categorized by a
+# built-in table; add to it or override it here
+gitmoji_categories: {}
+#  ":lipstick:": features
+
+# Commits left out of the changelog. Every field set in a rule must match:
+# message (regex), type, scope, author (regex on "Name <email>"), trailer
+# (Key or Key=value) and paths (the commit changes only matching files).
+# "changelog generate --show-excluded" lists what was left out.
+exclude:
+  - name: releases
+    type: chore
+    scope: release
+  - name: fixups
+    message: '^(fixup|squash)! '
+#  - name: dependency bumps
+#    type: chore
+#    scope: deps
+#  - name: opted out
+#    message: '\[skip changelog\]'
+#  - name: bots
+#    author: '\[bot\]'
+
+# Categorize commits by the files they change. match: all (every changed file
+# matches, the default) or any. precedence: first, before_keywords (after the
+# parsers and conventional prefixes, the default) or last.
+path_rules:
+  precedence: before_keywords
+  rules: []
+#    - name: docs
+#      paths: ["docs/", "*.md"]
+#      category: documentation
+#    - name: tests
+#      paths: ["*_test.go"]
+#      category: tests
+
 # Categories for changes
 categories:
   - breaking
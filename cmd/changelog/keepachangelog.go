@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"changelog-generator/internal/lib"
+)
+
+// keepAChangelogIntro is the standard preamble of a Keep a Changelog file
+const keepAChangelogIntro = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+
+`
+
+// GenerateKeepAChangelog creates a changelog following https://keepachangelog.com
+func GenerateKeepAChangelog(changelog *lib.Changelog, forge *lib.Forge, options lib.EntryOptions) string {
+	// The file always starts with an [Unreleased] section, even when it's empty
+	releases := changelog.Releases
+	if len(releases) > 0 && !releases[0].IsUnreleased() {
+		releases = append([]*lib.Release{{PreviousTag: releases[0].Tag}}, releases...)
+	}
+
+	md := keepAChangelogIntro
+	for _, release := range releases {
+		md += generateKeepAChangelogRelease(release, forge, options)
+	}
+
+	// Reference-style links for the version headings
+	var links []string
+	for _, release := range releases {
+		if link := keepAChangelogReference(release, forge); link != "" {
+			links = append(links, link)
+		}
+	}
+	if len(links) > 0 {
+		md += strings.Join(links, "\n") + "\n"
+	}
+
+	return md
+}
+
+// keepAChangelogHeading renders "## [Unreleased]" or "## [1.2.0] - 2026-10-01"
+func keepAChangelogHeading(release *lib.Release) string {
+	if release.IsUnreleased() {
+		return "## [Unreleased]\n"
+	}
+	return fmt.Sprintf("## [%s] - %s\n", release.Version, release.Date.Format("2006-01-02"))
+}
+
+// generateKeepAChangelogRelease renders one version section with the standard change types
+func generateKeepAChangelogRelease(release *lib.Release, forge *lib.Forge, options lib.EntryOptions) string {
+	md := keepAChangelogHeading(release) + "\n"
+
+	// Entries keep their category, which decides how their body is shown
+	type categorized struct {
+		entry    *lib.Entry
+		category lib.CommitCategory
+	}
+	grouped := make(map[string][]categorized)
+	for _, section := range release.Sections {
+		for _, entry := range section.Entries {
+			if title := keepAChangelogSection(section, entry, options); title != "" {
+				grouped[title] = append(grouped[title], categorized{entry, section.Category})
+			}
+		}
+	}
+
+	for _, title := range lib.KeepAChangelogSections {
+		entries := grouped[title]
+		if len(entries) == 0 {
+			continue
+		}
+
+		md += fmt.Sprintf("### %s\n\n", title)
+		for _, e := range entries {
+			md += fmt.Sprintf("- %s\n", formatEntry(e.entry, forge, options.CodeSpans))
+			md += markdownEntryBody(e.entry.Body, options.Body.Style(e.category), nil)
+		}
+		md += "\n"
+	}
+
+	return md
+}
+
+// keepAChangelogSection maps an entry to a Keep a Changelog section, or "" to leave it out
+func keepAChangelogSection(section *lib.Section, entry *lib.Entry, options lib.EntryOptions) string {
+	// Entries parsed from a Keep a Changelog file stay where they were
+	for _, title := range lib.KeepAChangelogSections {
+		if strings.EqualFold(section.Title, title) {
+			return title
+		}
+	}
+	return options.KeepAChangelogSection(section.Category, entry.Type)
+}
+
+// keepAChangelogReference renders the link definition for a version heading
+func keepAChangelogReference(release *lib.Release, forge *lib.Forge) string {
+	label := release.Version
+	to := release.Tag
+	if release.IsUnreleased() {
+		label = "Unreleased"
+		to = "HEAD"
+	}
+
+	url := ""
+	if release.PreviousTag != "" {
+		url = forge.CompareURL(release.PreviousTag, to)
+	} else if release.Tag != "" {
+		url = forge.TagURL(release.Tag)
+	}
+	if url == "" {
+		return ""
+	}
+	return fmt.Sprintf("[%s]: %s", label, url)
+}
+
+// insertKeepAChangelogRelease adds a new release to an existing Keep a Changelog file.
+// The release takes over the [Unreleased] section, keeping the entries written there
+// by hand, and the link references are updated. It returns the new file content and
+// the rendered release section.
+func insertKeepAChangelogRelease(existing string, release *lib.Release, forge *lib.Forge, options lib.EntryOptions) (string, string) {
+	if strings.TrimSpace(existing) == "" {
+		changelog := &lib.Changelog{Releases: []*lib.Release{release}}
+		return GenerateKeepAChangelog(changelog, forge, options), generateKeepAChangelogRelease(release, forge, options)
+	}
+
+	var body, links, unreleased []string
+	skipping := false
+	for _, line := range strings.SplitAfter(existing, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "## [Unreleased]"):
+			skipping = true // its changes are now part of the release
+		case strings.HasPrefix(trimmed, "## "):
+			skipping = false
+		case strings.HasPrefix(trimmed, "[Unreleased]:"):
+			continue
+		case strings.HasPrefix(trimmed, "[") && strings.Contains(trimmed, "]: "):
+			links = append(links, line)
+			continue
+		}
+		if skipping {
+			unreleased = append(unreleased, line)
+		} else {
+			body = append(body, line)
+		}
+	}
+
+	mergeUnreleasedEntries(release, strings.Join(unreleased, ""))
+	section := generateKeepAChangelogRelease(release, forge, options)
+	content := lib.InsertReleaseSection(strings.Join(body, ""), keepAChangelogIntro, "## [Unreleased]\n\n"+section)
+	content = strings.TrimRight(content, "\n") + "\n\n"
+
+	// New references go above the existing ones
+	next := &lib.Release{PreviousTag: release.Tag}
+	for _, ref := range []*lib.Release{next, release} {
+		if link := keepAChangelogReference(ref, forge); link != "" {
+			content += link + "\n"
+		}
+	}
+	return content + strings.Join(links, ""), section
+}
+
+// mergeUnreleasedEntries adds the entries of an existing [Unreleased] section to the
+// release, under the heading they were written under. Entries of the release's own
+// commits, as written by an earlier generate, are already in it and are skipped.
+func mergeUnreleasedEntries(release *lib.Release, unreleased string) {
+	parsed := lib.ParseMarkdownChangelog(unreleased, nil)
+	if len(parsed.Releases) == 0 {
+		return
+	}
+
+	var hashes []string
+	texts := make(map[string]bool)
+	for _, section := range release.Sections {
+		for _, entry := range section.Entries {
+			if entry.Hash != "" {
+				hashes = append(hashes, entry.Hash)
+			}
+			texts[entry.Text] = true
+		}
+	}
+	known := func(entry *lib.Entry) bool {
+		for _, hash := range hashes {
+			if entry.Hash != "" && (strings.HasPrefix(hash, entry.Hash) || strings.HasPrefix(entry.Hash, hash)) {
+				return true
+			}
+		}
+		return texts[entry.Text]
+	}
+
+	for _, section := range parsed.Releases[0].Sections {
+		merged := &lib.Section{Category: section.Category, Title: section.Title}
+		for _, entry := range section.Entries {
+			if !known(entry) {
+				merged.Entries = append(merged.Entries, entry)
+			}
+		}
+		if len(merged.Entries) > 0 {
+			release.Sections = append(release.Sections, merged)
+		}
+	}
+}
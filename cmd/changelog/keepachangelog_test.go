@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"changelog-generator/internal/lib"
+)
+
+func TestInsertKeepAChangelogReleaseKeepsUnreleasedEntries(t *testing.T) {
+	existing := keepAChangelogIntro + `## [Unreleased]
+
+### Added
+
+- Dark mode ([abc1234](https://github.com/acme/tool/commit/abc1234))
+- Thanks to the beta testers
+
+### Security
+
+- Rotated the signing key
+
+## [1.0.0] - 2026-01-05
+
+### Added
+
+- First release
+`
+	release := &lib.Release{Version: "1.1.0", Tag: "v1.1.0", Date: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), Sections: []*lib.Section{
+		{Category: lib.CategoryFeature, Entries: []*lib.Entry{{Hash: "abc1234", Text: "Dark mode", Type: "feat"}}},
+		{Category: lib.CategoryFix, Entries: []*lib.Entry{{Hash: "def5678", Text: "Handle empty input", Type: "fix"}}},
+	}}
+
+	content, section := insertKeepAChangelogRelease(existing, release, nil, lib.EntryOptions{})
+	want := `## [1.1.0] - 2026-02-01
+
+### Added
+
+- Dark mode ([abc1234])
+- Thanks to the beta testers
+
+### Fixed
+
+- Handle empty input ([def5678])
+
+### Security
+
+- Rotated the signing key
+
+`
+	if section != want {
+		t.Errorf("section =\n%s\nwant\n%s", section, want)
+	}
+	if !strings.Contains(content, "## [Unreleased]\n\n"+want+"## [1.0.0] - 2026-01-05") {
+		t.Errorf("the release doesn't replace the [Unreleased] section:\n%s", content)
+	}
+}
+
+func TestKeepAChangelogSection(t *testing.T) {
+	configured := lib.EntryOptions{KeepAChangelog: map[string]string{"docs": "Changed", "perf": "", "features": "Changed"}}
+	tests := []struct {
+		name     string
+		category lib.CommitCategory
+		entry    lib.Entry
+		options  lib.EntryOptions
+		want     string
+	}{
+		{"feature", lib.CategoryFeature, lib.Entry{Type: "feat", Text: "Add dark mode"}, lib.EntryOptions{}, "Added"},
+		{"fix that removes something", lib.CategoryFix, lib.Entry{Type: "fix", Text: "remove flicker"}, lib.EntryOptions{}, "Fixed"},
+		{"fix mentioning security", lib.CategoryFix, lib.Entry{Type: "fix", Text: "Link the security policy"}, lib.EntryOptions{}, "Fixed"},
+		{"security type", lib.CategoryOther, lib.Entry{Type: "security", Text: "Rotate keys"}, lib.EntryOptions{}, "Security"},
+		{"revert", lib.CategoryOther, lib.Entry{Type: "revert", Text: "Old parser"}, lib.EntryOptions{}, "Removed"},
+		{"breaking feature", lib.CategoryBreaking, lib.Entry{Type: "feat", Text: "New API"}, configured, "Changed"},
+		{"docs left out", lib.CategoryDocs, lib.Entry{Type: "docs", Text: "Guide"}, lib.EntryOptions{}, ""},
+		{"docs configured", lib.CategoryDocs, lib.Entry{Type: "docs", Text: "Guide"}, configured, "Changed"},
+		{"type configured out", lib.CategoryPerformance, lib.Entry{Type: "perf", Text: "Faster"}, configured, ""},
+		{"category configured", lib.CategoryFeature, lib.Entry{Type: "feat", Text: "Dark mode"}, configured, "Changed"},
+		{"non-conventional", lib.CategoryOther, lib.Entry{Text: "Update things"}, lib.EntryOptions{}, "Changed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			section := &lib.Section{Category: tt.category}
+			if got := keepAChangelogSection(section, &tt.entry, tt.options); got != tt.want {
+				t.Errorf("keepAChangelogSection() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -23,6 +23,9 @@ var rootCmd = &cobra.Command{
 		fmt.Println("Available commands:")
 		fmt.Println("  init      - Initialize configuration")
 		fmt.Println("  generate  - Generate a changelog")
+		fmt.Println("  release   - Update the changelog, commit and tag")
+		fmt.Println("  next-version - Calculate the next version")
+		fmt.Println("  bump      - Update the version in project files")
 		fmt.Println()
 		fmt.Println("Run 'changelog --help' for more information")
 	},
@@ -32,8 +35,6 @@ func main() {
 	//Execute the root command
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
+		os.Exit(1)
 	}
-	os.Exit(1)
-	fmt.Println("Hello, world!")
-
 }
@@ -3,120 +3,195 @@ package main
 import (
 	"fmt"
 	"os"
-	"time"
+	"regexp"
+	"strings"
 
 	"changelog-generator/internal/lib"
 )
 
-// GenerateMarkdown creates a formatted markdown changelog
-func GenerateMarkdown(commits []*lib.Commit, projectName, version string) string {
+// GenerateMarkdown creates a formatted markdown changelog.
+// forge may be nil, in which case commit hashes are not linked,
+// and catalog may be nil for English.
+func GenerateMarkdown(changelog *lib.Changelog, forge *lib.Forge, catalog *lib.Catalog, entries lib.EntryOptions) string {
 	// Start with header
-	md := fmt.Sprintf("# Changelog - %s\n\n", projectName)
-	md += fmt.Sprintf("## Version %s\n", version)
-	md += fmt.Sprintf("**Generated:** %s\n\n", time.Now().Format("January 2, 2006"))
-
-	// Group commits by category
-	groups := lib.GroupCommitsByCategory(commits)
-
-	// Define order of categories
-	categories := []lib.CommitCategory{
-		lib.CategoryBreaking,
-		lib.CategoryFeature,
-		lib.CategoryFix,
-		lib.CategoryPerformance,
-		lib.CategoryRefactor,
-		lib.CategoryDocs,
-		lib.CategoryTest,
-		lib.CategoryChore,
-		lib.CategoryOther,
+	md := generateMarkdownHeader(changelog.Title, catalog)
+	for _, release := range changelog.Releases {
+		md += generateMarkdownRelease(release, forge, catalog, entries)
 	}
 
-	// Add each category
-	for _, category := range categories {
-		categoryCommits, exists := groups[category]
-		if !exists || len(categoryCommits) == 0 {
-			continue // Skip empty categories
-		}
+	// Add footer
+	md += "---\n"
+	md += fmt.Sprintf("*%s: %d*\n", catalog.T("total_commits"), changelog.EntryCount())
+
+	return md
+}
+
+// generateMarkdownHeader renders the title at the top of the changelog
+func generateMarkdownHeader(projectName string, catalog *lib.Catalog) string {
+	return fmt.Sprintf("# %s - %s\n\n", catalog.T("changelog"), escapeMarkdown(projectName, false))
+}
+
+// generateMarkdownRelease renders one version section of the changelog
+func generateMarkdownRelease(release *lib.Release, forge *lib.Forge, catalog *lib.Catalog, entries lib.EntryOptions) string {
+	md := fmt.Sprintf("## %s %s\n", catalog.T("version"), escapeMarkdown(release.Version, false))
+	if release.IsUnreleased() {
+		md = fmt.Sprintf("## %s\n", catalog.T("unreleased"))
+	}
+	if !release.Date.IsZero() {
+		md += fmt.Sprintf("**%s:** %s\n", catalog.T("generated"), catalog.FormatDate(release.Date))
+	}
+	md += "\n"
+	if link := compareLink(release, forge); link != "" {
+		md += fmt.Sprintf("**%s:** %s\n\n", catalog.T("compare"), link)
+	}
 
+	// Add each category
+	for _, section := range release.Sections {
 		// Category header
-		md += fmt.Sprintf("### %s\n\n", category)
+		title := categoryTitle(section.Category, catalog)
+		if entries.GitmojiHeadings {
+			title = gitmojiTitle(section.Category, title)
+		}
+		md += fmt.Sprintf("### %s\n\n", title)
 
-		// List commits
-		for _, commit := range categoryCommits {
-			// Clean up the commit message (remove prefixes)
-			message := cleanCommitMessage(commit.Message)
-			md += fmt.Sprintf("- %s ([%s])\n", message, commit.Hash)
+		// List entries
+		style := entries.Body.Style(section.Category)
+		for _, entry := range section.Entries {
+			md += fmt.Sprintf("- %s\n", formatEntry(entry, forge, entries.CodeSpans))
+			md += markdownEntryBody(entry.Body, style, catalog)
 		}
 
 		md += "\n"
 	}
 
-	// Add footer
-	md += "---\n"
-	md += fmt.Sprintf("*Total commits: %d*\n", len(commits))
+	// Thank the people who made this release
+	md += generateContributors(release.Contributors, catalog)
 
 	return md
 }
 
-// cleanCommitMessage removes conventional commit prefixes
-func cleanCommitMessage(msg string) string {
-	// Remove trailing newlines
-	cleaned := ""
-	for _, c := range msg {
-		if c == '\n' {
-			break
-		}
-		cleaned += string(c)
-	}
-
-	// Remove conventional commit prefix (feat:, fix:, etc.)
-	prefixes := []string{"feat:", "fix:", "docs:", "chore:", "test:", "refactor:", "perf:"}
-	for _, prefix := range prefixes {
-		if len(cleaned) > len(prefix) && cleaned[:len(prefix)] == prefix {
-			// Remove prefix and trim space
-			cleaned = cleaned[len(prefix):]
-			// Remove leading space
-			if len(cleaned) > 0 && cleaned[0] == ' ' {
-				cleaned = cleaned[1:]
-			}
-			break
-		}
+// generateContributors renders the contributors section
+func generateContributors(contributors []*lib.Contributor, catalog *lib.Catalog) string {
+	if len(contributors) == 0 {
+		return ""
 	}
 
-	// Remove scope: feat(auth): -> just remove the (auth) part
-	// Find and remove text between ( and ):
-	result := ""
-	skipUntil := rune(0)
-	for _, c := range cleaned {
-		if c == '(' && skipUntil == 0 {
-			skipUntil = ')'
-			continue
+	md := fmt.Sprintf("### %s\n\n", catalog.T("contributors"))
+	for _, contributor := range contributors {
+		md += fmt.Sprintf("- %s", escapeMarkdown(contributor.Name, false))
+		if contributor.FirstTime {
+			md += fmt.Sprintf(" *(%s)*", catalog.T("first_contribution"))
 		}
-		if skipUntil != 0 {
-			if c == skipUntil {
-				skipUntil = 0
-				// Skip the colon and space after )
-				continue
-			}
-			continue
-		}
-		result += string(c)
+		md += "\n"
 	}
+	return md + "\n"
+}
 
-	// Remove leading ': '
-	if len(result) > 2 && result[:2] == ": " {
-		result = result[2:]
+// markdownEntryBody renders the body of an entry below its list item, indented so
+// that it stays part of the item
+func markdownEntryBody(body, style string, catalog *lib.Catalog) string {
+	if body == "" || style == "" || style == lib.BodyNone {
+		return ""
 	}
 
-	// Capitalize first letter
-	if len(result) > 0 {
-		first := result[0]
-		if first >= 'a' && first <= 'z' {
-			result = string(first-32) + result[1:]
+	text := ""
+	for _, line := range strings.Split(escapeMarkdownBody(body), "\n") {
+		if line != "" {
+			text += "  " + line
 		}
+		text += "\n"
 	}
 
-	return result
+	if style == lib.BodyDetails {
+		return fmt.Sprintf("\n  <details>\n  <summary>%s</summary>\n\n%s\n  </details>\n", catalog.T("details"), text)
+	}
+	return "\n" + text
+}
+
+// categoryTitle is the heading of a category section. Without a catalog the
+// category is written as it always has been.
+func categoryTitle(category lib.CommitCategory, catalog *lib.Catalog) string {
+	if catalog == nil {
+		return string(category)
+	}
+	return catalog.Category(category)
+}
+
+// gitmojiTitle starts a heading with the category's gitmoji, replacing one it
+// already has. Categories without a gitmoji keep their heading.
+func gitmojiTitle(category lib.CommitCategory, title string) string {
+	emoji := lib.CategoryEmoji(category)
+	if emoji == "" {
+		return title
+	}
+	_, title = lib.SplitGitmoji(strings.TrimSpace(title))
+	return emoji + " " + title
+}
+
+// compareLink renders a link to the diff between this release and the previous one
+func compareLink(release *lib.Release, forge *lib.Forge) string {
+	if release.PreviousTag == "" {
+		return ""
+	}
+	to := release.Tag
+	if to == "" {
+		to = "HEAD" // not tagged yet
+	}
+	url := forge.CompareURL(release.PreviousTag, to)
+	if url == "" {
+		return ""
+	}
+	return fmt.Sprintf("[%s...%s](%s)", escapeMarkdown(release.PreviousTag, false), escapeMarkdown(to, false), url)
+}
+
+// pullRequestRef matches references like "#123" in commit messages
+var pullRequestRef = regexp.MustCompile(`(^|[\s(])#(\d+)\b`)
+
+// formatEntry renders the text of an entry followed by its commit link, if it has one
+func formatEntry(entry *lib.Entry, forge *lib.Forge, codeSpans bool) string {
+	message := linkPullRequests(escapeMarkdown(entry.Text, codeSpans), forge, codeSpans)
+	if entry.Hash == "" {
+		return message
+	}
+	return fmt.Sprintf("%s (%s)", message, commitLink(entry, forge))
+}
+
+// commitLink renders a commit hash, linked to the forge when one is known
+func commitLink(entry *lib.Entry, forge *lib.Forge) string {
+	hash := entry.FullHash
+	if hash == "" {
+		hash = entry.Hash
+	}
+	url := forge.CommitURL(hash)
+	if url == "" {
+		return fmt.Sprintf("[%s]", entry.Hash)
+	}
+	return fmt.Sprintf("[%s](%s)", entry.Hash, url)
+}
+
+// linkPullRequests turns "#123" references into links to the pull request.
+// With codeSpans, references inside `code` are left as written.
+func linkPullRequests(message string, forge *lib.Forge, codeSpans bool) string {
+	if forge.PullRequestURL("1") == "" {
+		return message
+	}
+	link := func(text string) string {
+		return pullRequestRef.ReplaceAllStringFunc(text, func(match string) string {
+			parts := pullRequestRef.FindStringSubmatch(match)
+			return fmt.Sprintf("%s[#%s](%s)", parts[1], parts[2], forge.PullRequestURL(parts[2]))
+		})
+	}
+	if !codeSpans {
+		return link(message)
+	}
+
+	out := ""
+	last := 0
+	for _, span := range codeSpan.FindAllStringIndex(message, -1) {
+		out += link(message[last:span[0]]) + message[span[0]:span[1]]
+		last = span[1]
+	}
+	return out + link(message[last:])
 }
 
 // SaveMarkdown saves the markdown content to a file
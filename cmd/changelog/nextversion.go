@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"changelog-generator/internal/lib"
+
+	"github.com/spf13/cobra"
+)
+
+// Flags for next-version command
+var (
+	nextVersionPre    string
+	nextVersionFormat string
+	nextVersionPrefix string
+)
+
+// nextVersionOutput is the machine-readable form of the next-version result
+type nextVersionOutput struct {
+	Previous    string `json:"previous,omitempty"`
+	PreviousTag string `json:"previous_tag,omitempty"`
+	Next        string `json:"next"`
+	NextTag     string `json:"next_tag"`
+	Bump        string `json:"bump"`
+	Commits     int    `json:"commits"`
+}
+
+// nextVersionCmd represents the next-version command
+var nextVersionCmd = &cobra.Command{
+	Use:   "next-version",
+	Short: "Calculate the next version",
+	Long: `Find the latest version tag and work out the next version from the
+commits made since then.
+
+  - Breaking changes bump the major version
+  - Features bump the minor version
+  - Anything else bumps the patch version
+
+While the major version is 0, breaking changes bump the minor version
+and features bump the patch version.
+
+With versioning.scheme set to calver, the date part comes from today and
+the counter (e.g. MICRO) increments for releases on the same date.
+Pre-release channels (--pre beta) number their builds: 1.3.0-beta.1,
+1.3.0-beta.2, ...`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Errors go to stderr so stdout stays machine-readable
+		config, err := lib.LoadConfig(".changelogrc.yaml")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		repo, err := lib.OpenRepository(config.Git.RepositoryPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening repository: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !cmd.Flags().Changed("tag-prefix") {
+			nextVersionPrefix = config.Versioning.TagPrefix
+		}
+
+		scheme, err := lib.NewVersionScheme(config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error in versioning config: %v\n", err)
+			os.Exit(1)
+		}
+
+		classifier, err := lib.NewClassifier(repo, config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error in categorization config: %v\n", err)
+			os.Exit(1)
+		}
+
+		result, err := lib.CalculateNextVersion(repo, lib.NextVersionOptions{
+			TagPrefix:  nextVersionPrefix,
+			Scheme:     scheme,
+			PreRelease: nextVersionPre,
+			Classifier: classifier,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error calculating next version: %v\n", err)
+			os.Exit(1)
+		}
+
+		switch nextVersionFormat {
+		case "text":
+			fmt.Println(result.Next.String())
+		case "json":
+			output := nextVersionOutput{
+				Next:    result.Next.String(),
+				NextTag: nextVersionPrefix + result.Next.String(),
+				Bump:    string(result.Bump),
+				Commits: len(result.Commits),
+			}
+			if result.Previous != nil {
+				output.Previous = result.Previous.Version.String()
+				output.PreviousTag = result.Previous.Name
+			}
+
+			data, err := json.MarshalIndent(output, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding output: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown format %q (use text or json)\n", nextVersionFormat)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(nextVersionCmd)
+
+	nextVersionCmd.Flags().StringVar(&nextVersionPre, "pre", "", "Pre-release channel (e.g. alpha, beta, rc)")
+	nextVersionCmd.Flags().StringVar(&nextVersionFormat, "format", "text", "Output format: text or json")
+	nextVersionCmd.Flags().StringVar(&nextVersionPrefix, "tag-prefix", "", "Prefix of version tags (default from versioning.tag_prefix)")
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"changelog-generator/internal/lib"
+)
+
+// maintainerIdentity matches "Full Name <email>"
+var maintainerIdentity = regexp.MustCompile(`^[^<>]+ <[^<>@\s]+@[^<>\s]+>$`)
+
+// packageNameUnsafe matches characters that aren't allowed in package names
+var packageNameUnsafe = regexp.MustCompile(`[^a-z0-9.+-]+`)
+
+// packaging fills in the defaults for the debian and rpm formats and checks the maintainer
+func packaging(config *lib.Config) (lib.PackagingOptions, error) {
+	options := config.Packaging
+	if options.Name == "" {
+		options.Name = strings.Trim(packageNameUnsafe.ReplaceAllString(strings.ToLower(config.Project.Name), "-"), "-")
+	}
+	if options.Name == "" {
+		return options, fmt.Errorf("packaging.name is not set")
+	}
+	if !maintainerIdentity.MatchString(options.Maintainer) {
+		return options, fmt.Errorf("packaging.maintainer must look like \"Full Name <email>\", got %q", options.Maintainer)
+	}
+	if options.Distribution == "" {
+		options.Distribution = "unstable"
+	}
+	if options.Urgency == "" {
+		options.Urgency = "medium"
+	}
+	if options.Revision == "" {
+		options.Revision = "1"
+	}
+	return options, nil
+}
+
+// packageVersion converts a version to the package version, e.g. "1.3.0-rc.1" to "1.3.0~rc.1-1".
+// The tilde makes pre-releases sort before the final release in both dpkg and rpm.
+func packageVersion(version, revision string) string {
+	return strings.Replace(version, "-", "~", 1) + "-" + revision
+}
+
+// packageDate is the date of a release, or now for releases without one
+func packageDate(release *lib.Release) time.Time {
+	if release.Date.IsZero() {
+		return time.Now()
+	}
+	return release.Date
+}
+
+// packageEntry is an entry of a package changelog and the body shown below it
+type packageEntry struct {
+	text string
+	body string
+}
+
+// packageEntries lists the entries of a release in section order, with the bodies
+// that the body options show. Package changelogs are plain text, so a body shown
+// in a details block is written out like an indented one.
+func packageEntries(release *lib.Release, body lib.BodyOptions) []packageEntry {
+	var entries []packageEntry
+	for _, section := range release.Sections {
+		style := body.Style(section.Category)
+		for _, entry := range section.Entries {
+			e := packageEntry{text: entry.Text}
+			if style != "" && style != lib.BodyNone {
+				e.body = entry.Body
+			}
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// wrapBody wraps each line of a body with the given indent, leaving out blank lines
+func wrapBody(body, indent string) string {
+	out := ""
+	for _, line := range strings.Split(body, "\n") {
+		if strings.TrimSpace(line) != "" {
+			out += wrapText(line, 80, indent, indent)
+		}
+	}
+	return out
+}
+
+// GenerateDebianChangelog renders the releases in debian/changelog syntax.
+// Unreleased changes are left out, since they don't have a version yet.
+func GenerateDebianChangelog(changelog *lib.Changelog, config *lib.Config) (string, error) {
+	options, err := packaging(config)
+	if err != nil {
+		return "", err
+	}
+
+	out := ""
+	for _, release := range changelog.Releases {
+		if !release.IsUnreleased() {
+			out += generateDebianRelease(release, options, config.Output.Entries.Body)
+		}
+	}
+	return strings.TrimRight(out, "\n") + "\n", nil
+}
+
+// generateDebianRelease renders one debian/changelog entry
+func generateDebianRelease(release *lib.Release, options lib.PackagingOptions, body lib.BodyOptions) string {
+	out := fmt.Sprintf("%s (%s) %s; urgency=%s\n\n", options.Name,
+		packageVersion(release.Version, options.Revision), options.Distribution, options.Urgency)
+
+	entries := packageEntries(release, body)
+	if len(entries) == 0 {
+		entries = []packageEntry{{text: "New upstream release."}}
+	}
+	for _, entry := range entries {
+		out += wrapText(entry.text, 80, "  * ", "    ")
+		out += wrapBody(entry.body, "    ")
+	}
+
+	out += fmt.Sprintf("\n -- %s  %s\n\n", options.Maintainer, packageDate(release).Format(time.RFC1123Z))
+	return out
+}
+
+// GenerateRPMChangelog renders the releases as the %changelog section of a spec file
+func GenerateRPMChangelog(changelog *lib.Changelog, config *lib.Config) (string, error) {
+	options, err := packaging(config)
+	if err != nil {
+		return "", err
+	}
+
+	out := "%changelog\n"
+	for _, release := range changelog.Releases {
+		if !release.IsUnreleased() {
+			out += generateRPMRelease(release, options, config.Output.Entries.Body)
+		}
+	}
+	return strings.TrimRight(out, "\n") + "\n", nil
+}
+
+// generateRPMRelease renders one %changelog entry
+func generateRPMRelease(release *lib.Release, options lib.PackagingOptions, body lib.BodyOptions) string {
+	out := fmt.Sprintf("* %s %s - %s\n", packageDate(release).Format("Mon Jan 02 2006"),
+		options.Maintainer, packageVersion(release.Version, options.Revision))
+
+	entries := packageEntries(release, body)
+	if len(entries) == 0 {
+		entries = []packageEntry{{text: "New upstream release"}}
+	}
+	for _, entry := range entries {
+		// A leading % would be read as an rpm macro
+		out += wrapText(strings.ReplaceAll(entry.text, "%", "%%"), 80, "- ", "  ")
+		out += wrapBody(strings.ReplaceAll(entry.body, "%", "%%"), "  ")
+	}
+	return out + "\n"
+}
+
+// insertRPMRelease adds a release to the top of the %changelog section of a spec file
+func insertRPMRelease(existing, section string) string {
+	if strings.TrimSpace(existing) == "" {
+		return "%changelog\n" + section
+	}
+
+	lines := strings.SplitAfter(existing, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "%changelog" {
+			if !strings.HasSuffix(line, "\n") {
+				lines[i] += "\n"
+			}
+			return strings.Join(lines[:i+1], "") + section + strings.Join(lines[i+1:], "")
+		}
+	}
+
+	// No %changelog section yet, so add one at the end
+	if !strings.HasSuffix(existing, "\n") {
+		existing += "\n"
+	}
+	return existing + "\n%changelog\n" + section
+}
+
+// wrapText wraps text to the given width, starting with prefix and indenting
+// the following lines with indent
+func wrapText(text string, width int, prefix, indent string) string {
+	out := ""
+	line := prefix
+	for _, word := range strings.Fields(text) {
+		if line != prefix && line != indent && len(line)+1+len(word) > width {
+			out += line + "\n"
+			line = indent
+		}
+		if line != prefix && line != indent {
+			line += " "
+		}
+		line += word
+	}
+	return out + line + "\n"
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"text/tabwriter"
+	"time"
+
+	"changelog-generator/internal/lib"
+
+	"github.com/spf13/cobra"
+)
+
+// Flags for query command
+var (
+	queryTypes      []string
+	queryScopes     []string
+	queryCategories []string
+	queryBreaking   bool
+	queryAuthor     string
+	queryPaths      []string
+	queryAfter      string
+	queryBefore     string
+	queryTrailers   []string
+	queryGrep       string
+	queryFormat     string
+
+	queryIncludeExcluded bool
+)
+
+// queryCmd represents the query command
+var queryCmd = &cobra.Command{
+	Use:   "query [revision range]",
+	Short: "List commits matching filters",
+	Long: `List the commits in a revision range that match every given filter,
+parsed the same way generate parses them.
+
+The range is read like git log: "v1.0.0..v2.0.0", "v1.0.0.." (up to HEAD),
+or a single revision for its whole history. The default is HEAD.
+
+Examples:
+  changelog query v1.2.0.. --type feat --type fix
+  changelog query --breaking --format json
+  changelog query --path internal/lib --author ann
+  changelog query --trailer Reviewed-by=ann --after 2026-01-01`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		filter, err := queryFilter()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+			os.Exit(1)
+		}
+		if queryFormat != "text" && queryFormat != "json" {
+			fmt.Fprintf(os.Stderr, " Error: unknown query format %q\n", queryFormat)
+			os.Exit(1)
+		}
+
+		config, err := lib.LoadConfig(".changelogrc.yaml")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error loading config: %v\n", err)
+			fmt.Fprintln(os.Stderr, " Tip: Run 'changelog init' to create a config file")
+			os.Exit(1)
+		}
+
+		repo, err := lib.OpenRepository(config.Git.RepositoryPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error opening repository: %v\n", err)
+			os.Exit(1)
+		}
+
+		classifier, err := lib.NewClassifier(repo, config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error in categorization config: %v\n", err)
+			os.Exit(1)
+		}
+
+		spec := "HEAD"
+		if len(args) > 0 {
+			spec = args[0]
+		}
+		from, to, err := lib.ResolveRevisionRange(repo, spec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+			os.Exit(1)
+		}
+		commits, err := lib.GetCommitsBetween(repo, from, to)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error getting commits: %v\n", err)
+			os.Exit(1)
+		}
+
+		matched, err := lib.FilterCommits(repo, commits, filter, classifier)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if queryFormat == "json" {
+			err = writeQueryJSON(os.Stdout, matched, classifier)
+		} else {
+			err = writeQueryText(os.Stdout, matched, classifier)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// queryFilter builds the commit filter from the flags
+func queryFilter() (lib.CommitFilter, error) {
+	filter := lib.CommitFilter{
+		Types:      queryTypes,
+		Scopes:     queryScopes,
+		Categories: queryCategories,
+		Breaking:   queryBreaking,
+		Paths:      queryPaths,
+
+		IncludeExcluded: queryIncludeExcluded,
+	}
+
+	for _, key := range queryCategories {
+		if _, ok := lib.CategoryForKey(key); !ok {
+			return filter, fmt.Errorf("unknown category %q", key)
+		}
+	}
+
+	var err error
+	if queryAuthor != "" {
+		if filter.Author, err = regexp.Compile("(?i)" + queryAuthor); err != nil {
+			return filter, fmt.Errorf("invalid --author pattern: %w", err)
+		}
+	}
+	if queryGrep != "" {
+		if filter.Grep, err = regexp.Compile(queryGrep); err != nil {
+			return filter, fmt.Errorf("invalid --grep pattern: %w", err)
+		}
+	}
+
+	// Dates are whole days in local time; --before excludes its own day
+	if queryAfter != "" {
+		if filter.After, err = time.ParseInLocation("2006-01-02", queryAfter, time.Local); err != nil {
+			return filter, fmt.Errorf("invalid --after date %q, expected YYYY-MM-DD", queryAfter)
+		}
+	}
+	if queryBefore != "" {
+		if filter.Before, err = time.ParseInLocation("2006-01-02", queryBefore, time.Local); err != nil {
+			return filter, fmt.Errorf("invalid --before date %q, expected YYYY-MM-DD", queryBefore)
+		}
+	}
+
+	for _, text := range queryTrailers {
+		trailer, err := lib.ParseTrailerFilter(text)
+		if err != nil {
+			return filter, err
+		}
+		filter.Trailers = append(filter.Trailers, trailer)
+	}
+	return filter, nil
+}
+
+// writeQueryText prints one line per commit
+func writeQueryText(w io.Writer, commits []*lib.Commit, classifier *lib.Classifier) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, commit := range commits {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", commit.Hash, commit.Date.Format("2006-01-02"),
+			commit.Author, classifier.Categorize(commit).Key(), classifier.Parse(commit.Message).Subject)
+	}
+	return table.Flush()
+}
+
+// queryCommit is a commit as written by query --format json
+type queryCommit struct {
+	Hash     string        `json:"hash"`
+	Author   string        `json:"author"`
+	Email    string        `json:"email"`
+	Date     time.Time     `json:"date"`
+	Type     string        `json:"type,omitempty"`
+	Scope    string        `json:"scope,omitempty"`
+	Breaking bool          `json:"breaking,omitempty"`
+	Category string        `json:"category"`
+	Subject  string        `json:"subject"`
+	Ticket   string        `json:"ticket,omitempty"`
+	Gitmoji  string        `json:"gitmoji,omitempty"`
+	Body     string        `json:"body,omitempty"`
+	Trailers []lib.Trailer `json:"trailers,omitempty"`
+}
+
+// writeQueryJSON writes the commits with their parsed fields as a JSON array
+func writeQueryJSON(w io.Writer, commits []*lib.Commit, classifier *lib.Classifier) error {
+	out := []queryCommit{}
+	for _, commit := range commits {
+		header := classifier.Parse(commit.Message)
+		out = append(out, queryCommit{
+			Hash:     commit.FullHash,
+			Author:   commit.Author,
+			Email:    commit.Email,
+			Date:     commit.Date,
+			Type:     header.Type,
+			Scope:    header.Scope,
+			Breaking: header.Breaking,
+			Category: classifier.Categorize(commit).Key(),
+			Subject:  header.Subject,
+			Ticket:   header.Ticket,
+			Gitmoji:  header.Gitmoji,
+			Body:     lib.CommitBody(commit.Message),
+			Trailers: lib.ParseTrailers(commit.Message),
+		})
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(queryCmd)
+
+	queryCmd.Flags().StringArrayVar(&queryTypes, "type", nil, "Conventional commit type, e.g. feat (repeatable)")
+	queryCmd.Flags().StringArrayVar(&queryScopes, "scope", nil, "Conventional commit scope (repeatable)")
+	queryCmd.Flags().StringArrayVar(&queryCategories, "category", nil, "Changelog category, e.g. fixes (repeatable)")
+	queryCmd.Flags().BoolVar(&queryBreaking, "breaking", false, "Only breaking changes")
+	queryCmd.Flags().StringVar(&queryAuthor, "author", "", "Regular expression matched against \"Name <email>\", ignoring case")
+	queryCmd.Flags().StringArrayVar(&queryPaths, "path", nil, "File, directory or glob the commit touches (repeatable)")
+	queryCmd.Flags().StringVar(&queryAfter, "after", "", "Only commits on or after this date (YYYY-MM-DD)")
+	queryCmd.Flags().StringVar(&queryBefore, "before", "", "Only commits before this date (YYYY-MM-DD)")
+	queryCmd.Flags().StringArrayVar(&queryTrailers, "trailer", nil, "Trailer the commit must have, as Key or Key=value (repeatable)")
+	queryCmd.Flags().StringVar(&queryGrep, "grep", "", "Regular expression matched against the whole message")
+	queryCmd.Flags().BoolVar(&queryIncludeExcluded, "include-excluded", false, "Also list commits left out by the exclude rules")
+	queryCmd.Flags().StringVar(&queryFormat, "format", "text", "Output format: text or json")
+}
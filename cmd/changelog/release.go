@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"changelog-generator/internal/lib"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/cobra"
+)
+
+// Flags for release command
+var (
+	releaseVersion string
+	releasePre     string
+	releasePrefix  string
+	releaseDryRun  bool
+
+	releaseShowExcluded bool
+)
+
+// releaseCmd represents the release command
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Update the changelog, commit and tag a new release",
+	Long: `Cut a new release in one step.
+
+This command will:
+  1. Calculate the next version (or use --version)
+  2. Add the release section to the changelog file
+  3. Update the version in the files listed under version_targets
+  4. Commit the changes
+  5. Create an annotated tag whose message is the release section
+
+Use --dry-run to see exactly what would change without touching anything.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := lib.LoadConfig(".changelogrc.yaml")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error loading config: %v\n", err)
+			fmt.Fprintln(os.Stderr)
+			fmt.Fprintln(os.Stderr, " Tip: Run 'changelog init' to create a config file")
+			os.Exit(1)
+		}
+
+		repo, err := lib.OpenRepository(config.Git.RepositoryPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error opening repository: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !cmd.Flags().Changed("tag-prefix") {
+			releasePrefix = config.Versioning.TagPrefix
+		}
+
+		scheme, err := lib.NewVersionScheme(config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error in versioning config: %v\n", err)
+			os.Exit(1)
+		}
+
+		classifier, err := lib.NewClassifier(repo, config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error in categorization config: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Work out which version we are releasing
+		result, err := lib.CalculateNextVersion(repo, lib.NextVersionOptions{
+			TagPrefix:  releasePrefix,
+			Scheme:     scheme,
+			PreRelease: releasePre,
+			Classifier: classifier,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error calculating version: %v\n", err)
+			os.Exit(1)
+		}
+
+		next := result.Next
+		if releaseVersion != "" {
+			next, err = scheme.Parse(strings.TrimPrefix(releaseVersion, releasePrefix))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		version := next.String()
+		tag := releasePrefix + version
+
+		if lib.TagExists(repo, tag) {
+			fmt.Fprintf(os.Stderr, " Error: tag %s already exists\n", tag)
+			os.Exit(1)
+		}
+
+		forge, err := lib.DetectForge(repo, config)
+		if err != nil {
+			forge = nil // links are optional
+		}
+
+		// Build the release section from the commits the exclusion rules keep
+		commits, excluded := result.Commits, result.Excluded
+		release := lib.BuildRelease(commits, version, time.Now(), classifier)
+		release.Tag = tag
+		if result.Previous != nil {
+			release.PreviousTag = result.Previous.Name
+		}
+		if config.Contributors.Enabled {
+			release.Contributors, err = collectContributors(repo, commits, config)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, " Error collecting contributors: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// Work out the new content of every file we touch
+		filename := config.Output.Filename
+		existing, err := os.ReadFile(filename)
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, " Error reading %s: %v\n", filename, err)
+			os.Exit(1)
+		}
+		created := os.IsNotExist(err)
+
+		// A final release replaces the sections of its pre-releases
+		content := string(existing)
+		if !next.IsPreRelease() {
+			content = lib.RemovePreReleaseSections(content, next, scheme, releasePrefix)
+		}
+
+		// Version targets are verified before anything is written
+		changes, err := lib.PlanVersionTargets(config.VersionTargets, version)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error updating version targets: %v\n", err)
+			os.Exit(1)
+		}
+		updated, section, err := insertRelease(content, release, config.Output.Format, forge, config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+			os.Exit(1)
+		}
+		changes = append([]*lib.TargetChange{{
+			Path:     filename,
+			Original: string(existing),
+			Updated:  updated,
+			Created:  created,
+		}}, changes...)
+
+		var paths []string
+		for _, change := range changes {
+			paths = append(paths, change.Path)
+		}
+
+		// Whatever else is staged would end up in the release commit
+		staged, err := lib.OtherStagedPaths(repo, paths)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(staged) > 0 {
+			fmt.Fprintf(os.Stderr, " Error: other changes are staged: %s\n", strings.Join(staged, ", "))
+			fmt.Fprintln(os.Stderr, " Commit or unstage them before releasing")
+			os.Exit(1)
+		}
+
+		message := strings.NewReplacer("{version}", version, "{tag}", tag).Replace(releaseCommitMessage(config))
+
+		fmt.Printf(" Releasing %s (%s bump, %d commits)\n", tag, result.Bump, len(result.Commits))
+		fmt.Println()
+		reportExcluded(os.Stdout, excluded, releaseShowExcluded)
+
+		if releaseDryRun {
+			for _, change := range changes {
+				fmt.Print(lib.UnifiedDiff("a/"+change.Path, "b/"+change.Path, change.Original, change.Updated))
+			}
+			fmt.Println()
+			fmt.Printf(" Would commit: %s\n", message)
+			fmt.Printf(" Would tag:    %s\n", tag)
+			fmt.Println()
+			fmt.Println(" Tag message:")
+			fmt.Println(section)
+			return
+		}
+
+		// Resolve the author first, so a missing identity leaves the tree untouched
+		signature, err := lib.DefaultSignature(repo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := lib.ApplyTargetChanges(changes); err != nil {
+			fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// A failed commit or tag puts the branch and the files back, so the release
+		// can simply be retried
+		head, err := repo.Head()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error reading HEAD: %v\n", err)
+			os.Exit(1)
+		}
+		hash, err := lib.CommitFiles(repo, paths, message, signature)
+		if err != nil {
+			failRelease(repo, head.Hash(), changes, err)
+		}
+		fmt.Printf(" Committed %s: %s\n", hash.String()[:7], message)
+
+		if err := lib.CreateAnnotatedTag(repo, tag, hash, section, signature); err != nil {
+			failRelease(repo, head.Hash(), changes, err)
+		}
+		fmt.Printf(" Tagged %s\n", tag)
+		fmt.Println()
+		fmt.Println(" Done! Push with: git push --follow-tags")
+	},
+}
+
+// failRelease resets the branch to head, restores the files a release changed
+// and exits with err
+func failRelease(repo *git.Repository, head plumbing.Hash, changes []*lib.TargetChange, err error) {
+	fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+	if err := lib.ResetToCommit(repo, head); err != nil {
+		fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+	}
+	if err := lib.RestoreTargetChanges(changes); err != nil {
+		fmt.Fprintf(os.Stderr, " Error restoring files: %v\n", err)
+	} else {
+		fmt.Fprintln(os.Stderr, " The changed files were restored")
+	}
+	os.Exit(1)
+}
+
+// releaseCommitMessage returns the configured release commit message template
+func releaseCommitMessage(config *lib.Config) string {
+	if config.Release.CommitMessage != "" {
+		return config.Release.CommitMessage
+	}
+	return "chore(release): {tag}"
+}
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+
+	releaseCmd.Flags().StringVar(&releaseVersion, "version", "", "Release this version instead of the calculated one")
+	releaseCmd.Flags().StringVar(&releasePre, "pre", "", "Pre-release channel (e.g. alpha, beta, rc)")
+	releaseCmd.Flags().StringVar(&releasePrefix, "tag-prefix", "", "Prefix of version tags (default from versioning.tag_prefix)")
+	releaseCmd.Flags().BoolVar(&releaseDryRun, "dry-run", false, "Show what would change without writing, committing or tagging")
+	releaseCmd.Flags().BoolVar(&releaseShowExcluded, "show-excluded", false, "List the commits left out by the exclude rules, and the rule for each")
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"changelog-generator/internal/lib"
+)
+
+// renderChangelog renders a whole changelog in the given output format.
+// config provides format options and may be nil.
+func renderChangelog(changelog *lib.Changelog, format string, forge *lib.Forge, config *lib.Config) (string, error) {
+	if config == nil {
+		config = &lib.Config{}
+	}
+	catalog, err := lib.LoadCatalog(config.Output.Locale, config.Output.Catalogs)
+	if err != nil {
+		return "", err
+	}
+
+	switch format {
+	case "", "markdown", "md":
+		return GenerateMarkdown(changelog, forge, catalog, config.Output.Entries), nil
+	case "keepachangelog":
+		return GenerateKeepAChangelog(changelog, forge, config.Output.Entries), nil
+	case "html":
+		return GenerateHTML(changelog, forge, catalog, config.Output.Entries, config.Output.HTML)
+	case "rss":
+		return GenerateRSS(changelog, forge, catalog, config.Output.Entries, config.Output.Feed)
+	case "atom":
+		return GenerateAtom(changelog, forge, catalog, config.Output.Entries, config.Output.Feed)
+	case "debian":
+		return GenerateDebianChangelog(changelog, config)
+	case "rpm":
+		return GenerateRPMChangelog(changelog, config)
+	case "json":
+		data, err := json.MarshalIndent(changelog, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		return string(data) + "\n", nil
+	}
+	return "", fmt.Errorf("unknown output format %q", format)
+}
+
+// parseChangelog reads a changelog written in the given input format. catalog is
+// the configured locale, in addition to the built-in ones, and may be nil.
+func parseChangelog(content, format string, catalog *lib.Catalog) (*lib.Changelog, error) {
+	switch format {
+	case "md", "markdown", "keepachangelog":
+		return lib.ParseMarkdownChangelog(content, catalog), nil
+	case "json":
+		changelog := &lib.Changelog{}
+		if err := json.Unmarshal([]byte(content), changelog); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		return changelog, nil
+	}
+	return nil, fmt.Errorf("unknown input format %q", format)
+}
+
+// insertRelease adds a release section to an existing changelog in the given format.
+// It returns the new file content and the rendered section.
+func insertRelease(existing string, release *lib.Release, format string, forge *lib.Forge, config *lib.Config) (string, string, error) {
+	switch format {
+	case "", "markdown", "md":
+		catalog, err := lib.LoadCatalog(config.Output.Locale, config.Output.Catalogs)
+		if err != nil {
+			return "", "", err
+		}
+		section := generateMarkdownRelease(release, forge, catalog, config.Output.Entries)
+		header := generateMarkdownHeader(config.Project.Name, catalog)
+		return lib.InsertReleaseSection(existing, header, section), section, nil
+	case "keepachangelog":
+		content, section := insertKeepAChangelogRelease(existing, release, forge, config.Output.Entries)
+		return content, section, nil
+	case "debian":
+		options, err := packaging(config)
+		if err != nil {
+			return "", "", err
+		}
+		section := generateDebianRelease(release, options, config.Output.Entries.Body)
+		return section + existing, section, nil
+	case "rpm":
+		options, err := packaging(config)
+		if err != nil {
+			return "", "", err
+		}
+		section := generateRPMRelease(release, options, config.Output.Entries.Body)
+		return insertRPMRelease(existing, section), section, nil
+	}
+	return "", "", fmt.Errorf("format %q can't be updated in place", format)
+}
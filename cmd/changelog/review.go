@@ -0,0 +1,479 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"changelog-generator/internal/lib"
+
+	"golang.org/x/term"
+)
+
+// reviewKeys is the key help at the bottom of the interactive review
+const reviewKeys = "↑/↓ select  c category  r reword  h hide  K/J move up/down  a/x accept/reject AI  w write  q quit"
+
+// Keys that aren't characters, as returned by readKey
+const (
+	keyUp rune = -1 - iota
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyEscape
+	keyBackspace
+	keyInterrupt
+)
+
+// reviewMode is what the keys of the interactive review do at the moment
+type reviewMode int
+
+const (
+	reviewBrowsing  reviewMode = iota // moving through the entries
+	reviewChoosing                    // picking a category for the selected entry
+	reviewRewording                   // typing new text for the selected entry
+)
+
+// reviewItem is an entry being reviewed
+type reviewItem struct {
+	owner    *reviewRelease
+	entry    *lib.Entry
+	category lib.CommitCategory
+	hidden   bool
+	accepted bool // the AI rewrite has been looked at and kept
+}
+
+// reviewRelease holds the entries of one release while they're reviewed
+type reviewRelease struct {
+	release *lib.Release
+	items   []*reviewItem
+	titles  map[lib.CommitCategory]string // section titles from a parsed changelog
+}
+
+// reviewRow is a line of the entry list; headings have no item
+type reviewRow struct {
+	text string
+	item *reviewItem
+}
+
+// reviewer is the state of the interactive review
+type reviewer struct {
+	releases []*reviewRelease
+	selected *reviewItem
+	mode     reviewMode
+	choice   int    // index into lib.CategoryOrder while choosing
+	input    []rune // the text being typed while rewording
+	status   string // what the last key did, shown above the key help
+	offset   int    // the first row on screen
+	done     bool
+	write    bool
+}
+
+// reviewChangelog opens a terminal UI on out that lists the entries of a changelog
+// grouped by release and category. Entries can be recategorized, reworded, hidden
+// and reordered, and AI rewrites accepted or rejected, before the changelog is
+// written. It returns false if the user quit without writing.
+func reviewChangelog(changelog *lib.Changelog, in *os.File, out io.Writer) (bool, error) {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return false, fmt.Errorf("--interactive needs a terminal")
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return false, fmt.Errorf("failed to set up the terminal: %w", err)
+	}
+	defer term.Restore(fd, state)
+
+	// The alternate screen keeps the progress output visible after the review
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(out, "\x1b[?25h\x1b[?1049l")
+
+	r := newReviewer(changelog)
+	keys := bufio.NewReader(in)
+	for !r.done {
+		width, height, err := term.GetSize(fd)
+		if err != nil || width <= 0 || height <= 0 {
+			width, height = 80, 24
+		}
+		fmt.Fprint(out, "\x1b[H\x1b[2J"+strings.Join(r.view(width, height), "\r\n"))
+
+		key, err := readKey(keys)
+		if err != nil {
+			return false, fmt.Errorf("failed to read the keyboard: %w", err)
+		}
+		r.handle(key)
+	}
+
+	if r.write {
+		for _, release := range r.releases {
+			release.apply()
+		}
+	}
+	return r.write, nil
+}
+
+// newReviewer starts a review of the changelog with its first entry selected
+func newReviewer(changelog *lib.Changelog) *reviewer {
+	r := &reviewer{}
+	for _, release := range changelog.Releases {
+		rr := &reviewRelease{release: release, titles: make(map[lib.CommitCategory]string)}
+		for _, section := range release.Sections {
+			rr.titles[section.Category] = section.Title
+			for _, entry := range section.Entries {
+				rr.items = append(rr.items, &reviewItem{owner: rr, entry: entry, category: section.Category})
+			}
+		}
+		r.releases = append(r.releases, rr)
+	}
+	if items := r.items(); len(items) > 0 {
+		r.selected = items[0]
+	}
+	return r
+}
+
+// readKey reads one key press from a terminal in raw mode. The arrow keys are
+// read from their escape sequences; other sequences are returned as 0.
+func readKey(in *bufio.Reader) (rune, error) {
+	r, _, err := in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	switch r {
+	case '\r', '\n':
+		return keyEnter, nil
+	case 127, '\b':
+		return keyBackspace, nil
+	case 3: // Ctrl-C
+		return keyInterrupt, nil
+	case 27:
+		// The terminal sends a sequence in one go, so a lone escape is the Escape key
+		if in.Buffered() == 0 {
+			return keyEscape, nil
+		}
+		if next, _, err := in.ReadRune(); err != nil || (next != '[' && next != 'O') {
+			return 0, err
+		}
+		for {
+			c, _, err := in.ReadRune()
+			if err != nil {
+				return 0, err
+			}
+			if c >= '@' && c <= '~' {
+				return map[rune]rune{'A': keyUp, 'B': keyDown, 'C': keyRight, 'D': keyLeft}[c], nil
+			}
+		}
+	}
+	return r, nil
+}
+
+// handle applies one key press
+func (r *reviewer) handle(key rune) {
+	r.status = ""
+	switch r.mode {
+	case reviewChoosing:
+		r.handleChoice(key)
+	case reviewRewording:
+		r.handleInput(key)
+	default:
+		r.handleBrowse(key)
+	}
+}
+
+// handleBrowse applies a key while moving through the entries
+func (r *reviewer) handleBrowse(key rune) {
+	switch key {
+	case keyUp, 'k':
+		r.selectNext(-1)
+		return
+	case keyDown, 'j':
+		r.selectNext(1)
+		return
+	case 'w':
+		r.done, r.write = true, true
+		return
+	case 'q', keyInterrupt:
+		r.done = true
+		return
+	}
+
+	item := r.selected
+	if item == nil {
+		return
+	}
+	switch key {
+	case 'c':
+		r.mode = reviewChoosing
+		for i, category := range lib.CategoryOrder {
+			if category == item.category {
+				r.choice = i
+			}
+		}
+	case 'r':
+		r.mode = reviewRewording
+		r.input = []rune(item.entry.Text)
+	case 'h', ' ':
+		item.hidden = !item.hidden
+	case 'K', 'J':
+		r.moveSelected(key == 'J')
+	case 'a', 'x':
+		if item.entry.OriginalText == "" {
+			r.status = "This entry wasn't rewritten by AI"
+		} else if key == 'x' {
+			item.entry.Text = item.entry.OriginalText
+			item.entry.OriginalText = ""
+			r.status = "AI rewrite rejected"
+		} else {
+			item.accepted = true
+			r.status = "AI rewrite accepted"
+		}
+	}
+}
+
+// handleChoice applies a key while picking a category
+func (r *reviewer) handleChoice(key rune) {
+	switch key {
+	case keyLeft, keyUp, 'h', 'k':
+		r.choice = (r.choice + len(lib.CategoryOrder) - 1) % len(lib.CategoryOrder)
+	case keyRight, keyDown, 'l', 'j':
+		r.choice = (r.choice + 1) % len(lib.CategoryOrder)
+	case keyEnter:
+		r.selected.category = lib.CategoryOrder[r.choice]
+		r.status = "Moved to " + strings.TrimSpace(string(r.selected.category))
+		r.mode = reviewBrowsing
+	case keyEscape, keyInterrupt:
+		r.mode = reviewBrowsing
+	}
+}
+
+// handleInput applies a key while typing the new text of an entry
+func (r *reviewer) handleInput(key rune) {
+	switch {
+	case key == keyEnter:
+		text := strings.TrimSpace(string(r.input))
+		if text == "" {
+			r.status = "The text can't be empty; Esc keeps the old text"
+			return
+		}
+		// A reworded entry is no longer an AI rewrite
+		r.selected.entry.Text = text
+		r.selected.entry.OriginalText = ""
+		r.mode = reviewBrowsing
+	case key == keyEscape || key == keyInterrupt:
+		r.mode = reviewBrowsing
+	case key == keyBackspace:
+		if len(r.input) > 0 {
+			r.input = r.input[:len(r.input)-1]
+		}
+	case key > 0 && unicode.IsPrint(key):
+		r.input = append(r.input, key)
+	}
+}
+
+// selectNext moves the selection by delta entries, stopping at the first and last
+func (r *reviewer) selectNext(delta int) {
+	items := r.items()
+	for i, item := range items {
+		if item == r.selected {
+			r.selected = items[max(0, min(len(items)-1, i+delta))]
+			return
+		}
+	}
+}
+
+// moveSelected swaps the selected entry with the one below or above it in its section
+func (r *reviewer) moveSelected(down bool) {
+	item := r.selected
+	var section []*reviewItem
+	for _, it := range item.owner.items {
+		if it.category == item.category {
+			section = append(section, it)
+		}
+	}
+	for i, it := range section {
+		if it != item {
+			continue
+		}
+		j := i - 1
+		if down {
+			j = i + 1
+		}
+		if j < 0 || j >= len(section) {
+			r.status = "The entry is already at the edge of its section"
+			return
+		}
+		if err := moveReviewItem(item, section[j]); err != nil {
+			r.status = err.Error()
+		}
+		return
+	}
+}
+
+// items lists the entries in the order they're shown
+func (r *reviewer) items() []*reviewItem {
+	var items []*reviewItem
+	for _, row := range r.rows() {
+		if row.item != nil {
+			items = append(items, row.item)
+		}
+	}
+	return items
+}
+
+// rows lays out the entries grouped by release and category
+func (r *reviewer) rows() []reviewRow {
+	var rows []reviewRow
+	for _, rr := range r.releases {
+		heading := "Version " + rr.release.Version
+		if rr.release.IsUnreleased() {
+			heading = "Unreleased"
+		}
+		rows = append(rows, reviewRow{text: heading})
+
+		for _, category := range lib.CategoryOrder {
+			first := true
+			for _, item := range rr.items {
+				if item.category != category {
+					continue
+				}
+				if first {
+					title := rr.titles[category]
+					if title == "" {
+						title = strings.TrimSpace(string(category))
+					}
+					rows = append(rows, reviewRow{text: "  " + title})
+					first = false
+				}
+
+				text := "    " + item.entry.Text
+				if item.entry.Hash != "" {
+					text = fmt.Sprintf("    [%s] %s", item.entry.Hash, item.entry.Text)
+				}
+				if item.hidden {
+					text += "  (hidden)"
+				}
+				if item.entry.OriginalText != "" && !item.accepted {
+					text += fmt.Sprintf("  (AI rewrite of %q)", item.entry.OriginalText)
+				}
+				rows = append(rows, reviewRow{text: text, item: item})
+			}
+		}
+		rows = append(rows, reviewRow{})
+	}
+	return rows
+}
+
+// view renders the screen: the entry list, scrolled to keep the selection
+// visible, with the status and the keys or the current prompt below it
+func (r *reviewer) view(width, height int) []string {
+	header := []string{fitWidth(" Review the changelog before it's written", width), ""}
+	footer := []string{fitWidth(" "+r.status, width), r.prompt(width)}
+
+	rows := r.rows()
+	space := max(1, height-len(header)-len(footer))
+	for i, row := range rows {
+		if row.item != nil && row.item == r.selected {
+			if i < r.offset {
+				r.offset = i
+			}
+			if i >= r.offset+space {
+				r.offset = i - space + 1
+			}
+		}
+	}
+	r.offset = max(0, min(r.offset, len(rows)-space))
+
+	lines := header
+	for i := r.offset; i < len(rows) && i < r.offset+space; i++ {
+		line := fitWidth(rows[i].text, width)
+		switch {
+		case rows[i].item == nil:
+		case rows[i].item == r.selected:
+			line = "\x1b[7m" + line + "\x1b[0m"
+		case rows[i].item.hidden:
+			line = "\x1b[2m" + line + "\x1b[0m"
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) < len(header)+space {
+		lines = append(lines, "")
+	}
+	return append(lines, footer...)
+}
+
+// prompt is the bottom line: the keys, the category picker or the text being typed
+func (r *reviewer) prompt(width int) string {
+	switch r.mode {
+	case reviewChoosing:
+		line := " Category (←/→, Enter, Esc):"
+		for i, category := range lib.CategoryOrder {
+			if i == r.choice {
+				line += " \x1b[7m" + category.Key() + "\x1b[0m"
+			} else {
+				line += " " + category.Key()
+			}
+		}
+		return line
+	case reviewRewording:
+		// Long text scrolls so the end being typed stays visible
+		label := " Reword (Enter, Esc): "
+		text := []rune(string(r.input))
+		if room := width - len([]rune(label)) - 1; room > 0 && len(text) > room {
+			text = text[len(text)-room:]
+		}
+		return label + string(text) + "\x1b[7m \x1b[0m"
+	}
+	return fitWidth(" "+reviewKeys, width)
+}
+
+// fitWidth cuts a line to the width of the terminal
+func fitWidth(line string, width int) string {
+	runes := []rune(line)
+	if width > 0 && len(runes) > width {
+		return string(runes[:width])
+	}
+	return line
+}
+
+// moveReviewItem moves an item to the position of another item in the same section
+func moveReviewItem(item, target *reviewItem) error {
+	if item.owner != target.owner || item.category != target.category {
+		return fmt.Errorf("entries can only be moved within their section")
+	}
+
+	r := item.owner
+	var items []*reviewItem
+	for _, it := range r.items {
+		if it != item {
+			items = append(items, it)
+		}
+	}
+
+	// Inserting at the target's old index puts the item before the target when
+	// moving up, and after it when moving down
+	for i, it := range r.items {
+		if it == target {
+			r.items = append(items[:i:i], append([]*reviewItem{item}, items[i:]...)...)
+			break
+		}
+	}
+	return nil
+}
+
+// apply writes the reviewed entries back into the release's sections
+func (r *reviewRelease) apply() {
+	r.release.Sections = nil
+	for _, category := range lib.CategoryOrder {
+		section := &lib.Section{Category: category, Title: r.titles[category]}
+		for _, item := range r.items {
+			if item.category == category && !item.hidden {
+				section.Entries = append(section.Entries, item.entry)
+			}
+		}
+		if len(section.Entries) > 0 {
+			r.release.Sections = append(r.release.Sections, section)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"changelog-generator/internal/lib"
+)
+
+// reviewEntries lists the texts of a release by section after the review
+func reviewEntries(release *lib.Release) []string {
+	var got []string
+	for _, section := range release.Sections {
+		for _, entry := range section.Entries {
+			got = append(got, string(section.Category)+": "+entry.Text)
+		}
+	}
+	return got
+}
+
+func TestReadKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []rune
+	}{
+		{"letters", "cw", []rune{'c', 'w'}},
+		{"arrows", "\x1b[A\x1b[B\x1bOC\x1b[D", []rune{keyUp, keyDown, keyRight, keyLeft}},
+		{"enter and backspace", "\r\x7f", []rune{keyEnter, keyBackspace}},
+		{"lone escape", "\x1b", []rune{keyEscape}},
+		{"unknown sequence", "\x1b[3~q", []rune{0, 'q'}},
+		{"ctrl-c", "\x03", []rune{keyInterrupt}},
+		{"unicode", "é", []rune{'é'}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := bufio.NewReader(strings.NewReader(tt.input))
+			for _, want := range tt.want {
+				got, err := readKey(in)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != want {
+					t.Errorf("readKey() = %d, want %d", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReviewerEditsEntries(t *testing.T) {
+	release := &lib.Release{Version: "1.2.0", Sections: []*lib.Section{
+		{Category: lib.CategoryFeature, Entries: []*lib.Entry{
+			{Hash: "aaaaaaa", Text: "Dark mode"},
+			{Hash: "bbbbbbb", Text: "Faster startup", OriginalText: "speed up start"},
+		}},
+		{Category: lib.CategoryFix, Entries: []*lib.Entry{
+			{Hash: "ccccccc", Text: "Handle empty input", OriginalText: "fix empty"},
+			{Hash: "ddddddd", Text: "Typo"},
+		}},
+	}}
+	r := newReviewer(&lib.Changelog{Releases: []*lib.Release{release}})
+
+	press := func(keys ...rune) {
+		for _, key := range keys {
+			r.handle(key)
+		}
+	}
+	press(keyDown, 'K')                          // move "Faster startup" above "Dark mode"
+	press('a')                                   // and keep its AI rewrite
+	press(keyDown, keyDown, 'x')                 // reject the rewrite of "Handle empty input"
+	press(keyDown, 'h')                          // hide "Typo"
+	press(keyUp, 'r', keyBackspace)              // reword "fix empty"
+	press([]rune("ied input")...)                //   to "fix emptied input"
+	press(keyEnter, 'c', keyLeft, keyEnter, 'w') // and move it to features
+	if !r.done || !r.write {
+		t.Fatal("w should finish the review and write")
+	}
+	r.releases[0].apply()
+
+	got := reviewEntries(release)
+	want := []string{
+		string(lib.CategoryFeature) + ": Faster startup",
+		string(lib.CategoryFeature) + ": Dark mode",
+		string(lib.CategoryFeature) + ": fix emptied input",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("entries = %q, want %q", got, want)
+	}
+	if entry := release.Sections[0].Entries[0]; entry.OriginalText == "" {
+		t.Error("an accepted AI rewrite should keep its original text")
+	}
+}
+
+func TestReviewerQuitKeepsChangelog(t *testing.T) {
+	release := &lib.Release{Version: "1.2.0", Sections: []*lib.Section{
+		{Category: lib.CategoryFix, Entries: []*lib.Entry{{Hash: "aaaaaaa", Text: "Typo"}}},
+	}}
+	r := newReviewer(&lib.Changelog{Releases: []*lib.Release{release}})
+	r.handle('h')
+	r.handle('r')
+	r.handle('q') // typed into the text, not a quit
+	if r.done {
+		t.Fatal("q while rewording should be typed")
+	}
+	r.handle(keyEscape)
+	r.handle('q')
+	if !r.done || r.write {
+		t.Fatal("q should finish the review without writing")
+	}
+	if release.Sections[0].Entries[0].Text != "Typo" {
+		t.Errorf("text = %q, want it unchanged", release.Sections[0].Entries[0].Text)
+	}
+}
+
+func TestReviewerViewKeepsSelectionVisible(t *testing.T) {
+	var entries []*lib.Entry
+	for _, text := range []string{"one", "two", "three", "four", "five", "six", "seven", "eight"} {
+		entries = append(entries, &lib.Entry{Text: text})
+	}
+	r := newReviewer(&lib.Changelog{Releases: []*lib.Release{{Version: "1.0.0", Sections: []*lib.Section{
+		{Category: lib.CategoryFeature, Entries: entries},
+	}}}})
+	for range 7 {
+		r.handle(keyDown)
+	}
+
+	lines := r.view(40, 8)
+	if len(lines) != 8 {
+		t.Fatalf("view has %d lines, want 8", len(lines))
+	}
+	if !strings.Contains(strings.Join(lines, "\n"), "\x1b[7m    eight") {
+		t.Errorf("the selected entry isn't on screen:\n%s", strings.Join(lines, "\n"))
+	}
+}
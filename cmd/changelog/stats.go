@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"changelog-generator/internal/lib"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/cobra"
+)
+
+// Flags for stats command
+var (
+	statsSince  string
+	statsTo     string
+	statsFormat string
+	statsLines  bool
+)
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show commit statistics per release",
+	Long: `Report commit counts by category, scope and author, the share of
+conventional commits and optionally the lines changed, for every release or for one
+commit range. Each release is compared with the one before it.
+
+Counting the lines added and deleted diffs every commit, which takes a while
+on a long history, so it's only done with --lines.
+
+Formats:
+  --format   table, json, csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := lib.LoadConfig(".changelogrc.yaml")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error loading config: %v\n", err)
+			fmt.Fprintln(os.Stderr, " Tip: Run 'changelog init' to create a config file")
+			os.Exit(1)
+		}
+
+		repo, err := lib.OpenRepository(config.Git.RepositoryPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error opening repository: %v\n", err)
+			os.Exit(1)
+		}
+
+		var ranges []*lib.ReleaseRange
+		if cmd.Flags().Changed("since") || cmd.Flags().Changed("to") {
+			// Without --since, the range goes back to the first commit
+			from := plumbing.ZeroHash
+			label := statsTo
+			if statsSince != "" {
+				from, err = lib.ResolveRevision(repo, statsSince)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+					os.Exit(1)
+				}
+				label = statsSince + ".." + statsTo
+			}
+			to, err := lib.ResolveRevision(repo, statsTo)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+				os.Exit(1)
+			}
+			commits, err := lib.GetCommitsBetween(repo, from, to)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, " Error getting commits: %v\n", err)
+				os.Exit(1)
+			}
+			// The range is dated by its last commit, like a release by its tag
+			date, err := lib.CommitDate(repo, to)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+				os.Exit(1)
+			}
+			ranges = []*lib.ReleaseRange{{Version: label, Date: date, Commits: commits}}
+		} else {
+			ranges, err = lib.ListReleaseRanges(repo, config)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, " Error getting commits: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		mailmapPath := config.Contributors.Mailmap
+		if mailmapPath == "" {
+			mailmapPath = ".mailmap"
+		}
+		mailmap, err := lib.LoadMailmap(filepath.Join(config.Git.RepositoryPath, mailmapPath))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		classifier, err := lib.NewClassifier(repo, config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error in categorization config: %v\n", err)
+			os.Exit(1)
+		}
+
+		stats, err := lib.CollectStats(repo, ranges, mailmap, classifier, statsLines)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error collecting stats: %v\n", err)
+			os.Exit(1)
+		}
+
+		switch statsFormat {
+		case "table":
+			err = writeStatsTable(os.Stdout, stats)
+		case "json":
+			err = writeStatsJSON(os.Stdout, stats)
+		case "csv":
+			err = writeStatsCSV(os.Stdout, stats)
+		default:
+			err = fmt.Errorf("unknown stats format %q", statsFormat)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, " Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// statsVersion is how a release is labeled in the stats
+func statsVersion(stats *lib.ReleaseStats) string {
+	if stats.Version == "" {
+		return "Unreleased"
+	}
+	return stats.Version
+}
+
+// writeStatsTable prints a summary row per release, then each release's breakdown
+func writeStatsTable(w io.Writer, all []*lib.ReleaseStats) error {
+	// Line columns only appear when lines were counted
+	lines := len(all) > 0 && all[0].Lines != nil
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if lines {
+		fmt.Fprintln(table, "VERSION\tDATE\tCOMMITS\tCONVENTIONAL\tADDED\tDELETED\tTREND")
+	} else {
+		fmt.Fprintln(table, "VERSION\tDATE\tCOMMITS\tCONVENTIONAL\tTREND")
+	}
+	for _, stats := range all {
+		trend := "-"
+		if stats.Trend != nil {
+			trend = fmt.Sprintf("%+d commits, %+.0f%% conventional", stats.Trend.Commits, stats.Trend.ConventionalPercent)
+			if stats.Trend.LinesChanged != nil {
+				trend += fmt.Sprintf(", %+d lines", *stats.Trend.LinesChanged)
+			}
+		}
+		fmt.Fprintf(table, "%s\t%s\t%d\t%.0f%%\t", statsVersion(stats),
+			stats.Date.Format("2006-01-02"), stats.Commits, stats.ConventionalPercent)
+		if stats.Lines != nil {
+			fmt.Fprintf(table, "+%d\t-%d\t", stats.Lines.Additions, stats.Lines.Deletions)
+		}
+		fmt.Fprintln(table, trend)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	for _, stats := range all {
+		fmt.Fprintf(w, "\n%s\n", statsVersion(stats))
+		fmt.Fprintf(w, "  Categories: %s\n", formatCounts(stats.Categories))
+		fmt.Fprintf(w, "  Scopes:     %s\n", formatCounts(stats.Scopes))
+		fmt.Fprintf(w, "  Authors:    %s\n", formatCounts(stats.Authors))
+	}
+	return nil
+}
+
+// formatCounts lists counts from the largest down, e.g. "features 5, fixes 3"
+func formatCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return "-"
+	}
+	var parts []string
+	for _, key := range sortedCounts(counts) {
+		parts = append(parts, fmt.Sprintf("%s %d", key, counts[key]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sortedCounts returns the keys of counts from the largest count down, then by name
+func sortedCounts(counts map[string]int) []string {
+	var keys []string
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// writeStatsJSON writes the stats as a JSON array, newest release first
+func writeStatsJSON(w io.Writer, all []*lib.ReleaseStats) error {
+	if all == nil {
+		all = []*lib.ReleaseStats{}
+	}
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// writeStatsCSV writes one "version,metric,key,value" row per number, which
+// spreadsheets can pivot however they like
+func writeStatsCSV(w io.Writer, all []*lib.ReleaseStats) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"version", "metric", "key", "value"})
+	for _, stats := range all {
+		version := statsVersion(stats)
+		row := func(metric, key string, value string) {
+			out.Write([]string{version, metric, key, value})
+		}
+
+		row("date", "", stats.Date.Format("2006-01-02"))
+		row("commits", "", strconv.Itoa(stats.Commits))
+		row("conventional", "", strconv.Itoa(stats.Conventional))
+		row("conventional_percent", "", strconv.FormatFloat(stats.ConventionalPercent, 'f', 1, 64))
+		if stats.Lines != nil {
+			row("additions", "", strconv.Itoa(stats.Lines.Additions))
+			row("deletions", "", strconv.Itoa(stats.Lines.Deletions))
+		}
+		for _, key := range sortedCounts(stats.Categories) {
+			row("category", key, strconv.Itoa(stats.Categories[key]))
+		}
+		for _, key := range sortedCounts(stats.Scopes) {
+			row("scope", key, strconv.Itoa(stats.Scopes[key]))
+		}
+		for _, key := range sortedCounts(stats.Authors) {
+			row("author", key, strconv.Itoa(stats.Authors[key]))
+		}
+		if stats.Trend != nil {
+			row("trend", "commits", strconv.Itoa(stats.Trend.Commits))
+			row("trend", "conventional_percent", strconv.FormatFloat(stats.Trend.ConventionalPercent, 'f', 1, 64))
+			if stats.Trend.LinesChanged != nil {
+				row("trend", "lines_changed", strconv.Itoa(*stats.Trend.LinesChanged))
+			}
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().StringVar(&statsSince, "since", "", "Start of a commit range instead of every release")
+	statsCmd.Flags().StringVar(&statsTo, "to", "HEAD", "End of the commit range")
+	statsCmd.Flags().StringVar(&statsFormat, "format", "table", "Output format: table, json or csv")
+	statsCmd.Flags().BoolVar(&statsLines, "lines", false, "Count the lines added and deleted (diffs every commit)")
+}
@@ -128,27 +128,28 @@ func (c *AIClient) callClaude(prompt string) (string, error) {
 	return text, nil
 }
 
-// ImproveAllCommits improves all commit messages using AI
-func (c *AIClient) ImproveAllCommits(commits []*Commit) {
-	fmt.Println("Using AI to improve commit messages...")
-	fmt.Println()
+// ImproveAllCommits improves all commit messages using AI, reporting progress to w
+func (c *AIClient) ImproveAllCommits(commits []*Commit, w io.Writer) {
+	fmt.Fprintln(w, "Using AI to improve commit messages...")
+	fmt.Fprintln(w)
 
 	for i, commit := range commits {
-		fmt.Printf("  Processing %d/%d: %s\n", i+1, len(commits), commit.Hash)
+		fmt.Fprintf(w, "  Processing %d/%d: %s\n", i+1, len(commits), commit.Hash)
 
 		improved, err := c.ImproveCommitMessage(commit)
 		if err != nil {
-			fmt.Printf("  Error: %v (using original)\n", err)
+			fmt.Fprintf(w, "  Error: %v (using original)\n", err)
 			continue
 		}
 
-		// Update the commit message
+		// Update the commit message, keeping the original so the rewrite can be rejected
+		commit.OriginalMessage = commit.Message
 		commit.Message = improved
 	}
 
-	fmt.Println()
-	fmt.Println(" AI processing complete!")
-	fmt.Println()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, " AI processing complete!")
+	fmt.Fprintln(w)
 }
 
 // cleanCommitMessageAI is a simple version for when API fails
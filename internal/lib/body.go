@@ -0,0 +1,76 @@
+package lib
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Ways of showing commit bodies in a changelog
+const (
+	BodyNone     = "none"     // subject line only
+	BodyIndented = "indented" // body text below the entry
+	BodyDetails  = "details"  // body in a collapsible block
+)
+
+// BodyOptions sets how commit bodies are shown, by category key ("features", "fixes", ...).
+// The "default" key applies to categories that aren't listed.
+type BodyOptions map[string]string
+
+// Style returns how bodies of the given category are shown
+func (o BodyOptions) Style(category CommitCategory) string {
+	if style, ok := o[category.Key()]; ok {
+		return style
+	}
+	if style, ok := o["default"]; ok {
+		return style
+	}
+	return BodyNone
+}
+
+// markdownBlock matches lines that start a Markdown block and must keep their own line
+var markdownBlock = regexp.MustCompile(`^(\s*([-*+]|\d+[.)])\s|\s*>|#|\s*\||    |\t)`)
+
+// CommitBody returns the body of a commit message: everything after the subject,
+// without trailers. Hard-wrapped paragraphs are joined into single lines;
+// lists, quotes, tables and code blocks are kept as written.
+func CommitBody(message string) string {
+	paragraphs := splitParagraphs(message)
+	if len(paragraphs) < 2 {
+		return ""
+	}
+	paragraphs = paragraphs[1:]
+
+	// Lines of the footer that aren't trailers stay in the body
+	if _, rest, ok := trailerBlock(paragraphs[len(paragraphs)-1]); ok {
+		paragraphs = paragraphs[:len(paragraphs)-1]
+		if len(rest) > 0 {
+			paragraphs = append(paragraphs, strings.Join(rest, "\n"))
+		}
+	}
+
+	var out []string
+	inFence := false
+	for _, paragraph := range paragraphs {
+		lines := strings.Split(paragraph, "\n")
+		keep := inFence
+		for _, line := range lines {
+			if strings.HasPrefix(strings.TrimSpace(line), "```") {
+				inFence = !inFence
+				keep = true
+			} else if markdownBlock.MatchString(line) {
+				keep = true
+			}
+		}
+
+		if keep {
+			out = append(out, strings.TrimRight(paragraph, " \t"))
+			continue
+		}
+		var words []string
+		for _, line := range lines {
+			words = append(words, strings.TrimSpace(line))
+		}
+		out = append(out, strings.Join(words, " "))
+	}
+	return strings.Join(out, "\n\n")
+}
@@ -0,0 +1,40 @@
+package lib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommitBodyStripsTrailers(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{"key value trailers", "feat: x\n\nExplain things.\n\nCo-authored-by: B <b@c>\nSigned-off-by: A <a@b>", "Explain things."},
+		{"mixed footer", "feat: x\n\nExplain things.\n\nBREAKING CHANGE: api removed\nRefs #12\nSigned-off-by: A <a@b>", "Explain things."},
+		{"continued trailer", "feat: x\n\nExplain things.\n\nBREAKING CHANGE: the api\n  is removed\nCloses #7", "Explain things."},
+		{"text beside a known trailer", "feat: x\n\nExplain things.\n\nThanks to the testers.\nSigned-off-by: A <a@b>", "Explain things.\n\nThanks to the testers."},
+		{"ordinary last paragraph", "feat: x\n\nExplain things.\n\nSee the guide\nfor details.", "Explain things.\n\nSee the guide for details."},
+		{"prose with a colon", "feat: x\n\nExplain things.\n\nThe parser now\nreads Refs: keys too.", "Explain things.\n\nThe parser now reads Refs: keys too."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CommitBody(tt.message); got != tt.want {
+				t.Errorf("CommitBody() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrailersMixedFooter(t *testing.T) {
+	message := "feat: x\n\nExplain things.\n\nBREAKING CHANGE: api removed\nRefs #12\nSigned-off-by: A <a@b>"
+	want := []Trailer{
+		{Key: "BREAKING CHANGE", Value: "api removed"},
+		{Key: "Refs", Value: "#12"},
+		{Key: "Signed-off-by", Value: "A <a@b>"},
+	}
+	if got := ParseTrailers(message); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTrailers() = %q, want %q", got, want)
+	}
+}
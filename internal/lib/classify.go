@@ -0,0 +1,518 @@
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// RuleResult is what one categorization rule found in a commit
+type RuleResult struct {
+	Rule     string         // e.g. "conventional prefix"
+	Matched  bool           // whether the rule applies to the commit
+	Category CommitCategory // the category the rule gives when it matched
+	Detail   string         // what the rule found, or what it looked for
+}
+
+// classifyRule is one step of categorizing a commit
+type classifyRule func(commit *Commit) RuleResult
+
+// Classifier categorizes commits with a list of rules tried in order.
+// The first rule that matches decides the category.
+type Classifier struct {
+	rules   []classifyRule
+	parsers []*commitParser
+	types   map[string]CommitCategory // commit type -> category, from type_categories
+	gitmoji map[string]CommitCategory // gitmoji key -> category, built in and from gitmoji_categories
+	exclude []*exclusionRule          // commits left out of the changelog
+
+	repo  *git.Repository     // for the files path rules look at
+	files map[string][]string // changed files by commit hash
+}
+
+// commitParser is a custom commit format from the config
+type commitParser struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// parserGroups are the named groups a custom parser may capture
+var parserGroups = map[string]bool{"type": true, "scope": true, "subject": true, "ticket": true, "breaking": true}
+
+// conventionalPrefixes are the commit types recognized at the start of a message, in the order they're checked
+var conventionalPrefixes = []struct {
+	prefix   string
+	category CommitCategory
+}{
+	{"feat", CategoryFeature},
+	{"fix", CategoryFix},
+	{"docs", CategoryDocs},
+	{"perf", CategoryPerformance},
+	{"refactor", CategoryRefactor},
+	{"test", CategoryTest},
+	{"chore", CategoryChore},
+}
+
+// markerRules are the built-in rules for explicitly marked commits
+func markerRules(gitmoji map[string]CommitCategory) []classifyRule {
+	return []classifyRule{
+		breakingRule(gitmoji),
+		conventionalPrefixRule,
+		gitmojiRule(gitmoji),
+	}
+}
+
+// keywordRules are the built-in rules that guess a category from words in the message
+func keywordRules() []classifyRule {
+	return []classifyRule{
+		keywordRule("feature keywords", CategoryFeature, []string{"add", "implement", "create", "new"}),
+		keywordRule("fix keywords", CategoryFix, []string{"fix", "bug", "issue", "resolve"}),
+		keywordRule("refactor keywords", CategoryRefactor, []string{"update", "improve"}),
+	}
+}
+
+// builtinRules are the rules every classifier ends with
+func builtinRules(gitmoji map[string]CommitCategory) []classifyRule {
+	rules := append(markerRules(gitmoji), keywordRules()...)
+	return append(rules, fallbackRule)
+}
+
+// defaultClassifier is used when no classifier is given
+var defaultClassifier = &Classifier{rules: builtinRules(builtinGitmojiTable)}
+
+// Where path rules go among the message rules
+const (
+	PathRulesFirst          = "first"           // before every message rule
+	PathRulesBeforeKeywords = "before_keywords" // after parsers and conventional prefixes, before keyword guesses
+	PathRulesLast           = "last"            // only before the fallback
+)
+
+// NewClassifier builds the classifier for a config: its custom parsers come
+// first, then the built-in rules, with the path rules placed by their precedence.
+// The repository is used to list the files a commit changes; it's only needed
+// for path rules. A nil config gives the built-in rules only.
+func NewClassifier(repo *git.Repository, config *Config) (*Classifier, error) {
+	c := &Classifier{types: make(map[string]CommitCategory), gitmoji: builtinGitmoji(), repo: repo, files: make(map[string][]string)}
+	if config == nil {
+		c.rules = builtinRules(c.gitmoji)
+		return c, nil
+	}
+
+	for commitType, key := range config.TypeCategories {
+		category, ok := CategoryForKey(key)
+		if !ok {
+			return nil, fmt.Errorf("unknown category %q for type %q in type_categories", key, commitType)
+		}
+		c.types[commitType] = category
+	}
+
+	for gitmoji, key := range config.GitmojiCategories {
+		category, ok := CategoryForKey(key)
+		if !ok {
+			return nil, fmt.Errorf("unknown category %q for %s in gitmoji_categories", key, gitmoji)
+		}
+		if !validGitmoji(gitmoji) {
+			return nil, fmt.Errorf("%q in gitmoji_categories is not a gitmoji shortcode or emoji", gitmoji)
+		}
+		c.gitmoji[gitmojiKey(gitmoji)] = category
+	}
+
+	for i, parser := range config.Parsers {
+		name := parser.Name
+		if name == "" {
+			name = fmt.Sprintf("parser %d", i+1)
+		}
+		pattern, err := regexp.Compile(parser.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for %s: %w", name, err)
+		}
+		captures := false
+		for _, group := range pattern.SubexpNames()[1:] {
+			if group == "" {
+				continue
+			}
+			if !parserGroups[group] {
+				return nil, fmt.Errorf("%s has unknown group %q; use type, scope, subject, ticket or breaking", name, group)
+			}
+			captures = captures || group == "type" || group == "breaking"
+		}
+		if !captures {
+			return nil, fmt.Errorf("%s must capture a type or breaking group", name)
+		}
+
+		p := &commitParser{name: name, pattern: pattern}
+		c.parsers = append(c.parsers, p)
+		c.rules = append(c.rules, c.parserRule(p))
+	}
+
+	for i, rule := range config.Exclude {
+		exclusion, err := newExclusionRule(rule, i)
+		if err != nil {
+			return nil, err
+		}
+		c.exclude = append(c.exclude, exclusion)
+	}
+
+	var pathRules []classifyRule
+	for i, rule := range config.PathRules.Rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("path rule %d", i+1)
+		}
+		if len(rule.Paths) == 0 {
+			return nil, fmt.Errorf("%s has no paths", rule.Name)
+		}
+		category, ok := CategoryForKey(rule.Category)
+		if !ok {
+			return nil, fmt.Errorf("unknown category %q in %s", rule.Category, rule.Name)
+		}
+		if rule.Match == "" {
+			rule.Match = "all"
+		}
+		if rule.Match != "all" && rule.Match != "any" {
+			return nil, fmt.Errorf("invalid match %q in %s, expected all or any", rule.Match, rule.Name)
+		}
+		pathRules = append(pathRules, c.pathRule(rule, category))
+	}
+
+	switch config.PathRules.Precedence {
+	case PathRulesFirst:
+		c.rules = append(pathRules, c.rules...)
+		c.rules = append(c.rules, builtinRules(c.gitmoji)...)
+	case PathRulesBeforeKeywords, "":
+		c.rules = append(c.rules, markerRules(c.gitmoji)...)
+		c.rules = append(c.rules, pathRules...)
+		c.rules = append(c.rules, keywordRules()...)
+		c.rules = append(c.rules, fallbackRule)
+	case PathRulesLast:
+		c.rules = append(c.rules, markerRules(c.gitmoji)...)
+		c.rules = append(c.rules, keywordRules()...)
+		c.rules = append(c.rules, pathRules...)
+		c.rules = append(c.rules, fallbackRule)
+	default:
+		return nil, fmt.Errorf("invalid path_rules precedence %q, expected first, before_keywords or last", config.PathRules.Precedence)
+	}
+	return c, nil
+}
+
+// Parse reads the subject line of a commit with the first custom parser that
+// matches it, or as a conventional commit
+func (c *Classifier) Parse(message string) CommitHeader {
+	if c != nil {
+		for _, p := range c.parsers {
+			if header, ok := p.parse(message, c.gitmojiTable()); ok {
+				return header
+			}
+		}
+	}
+	return parseCommitHeader(message, c.gitmojiTable())
+}
+
+// gitmojiTable returns the gitmoji the classifier knows, built in and configured
+func (c *Classifier) gitmojiTable() map[string]CommitCategory {
+	if c == nil || c.gitmoji == nil {
+		return builtinGitmojiTable
+	}
+	return c.gitmoji
+}
+
+// parse reads a message with a custom parser
+func (p *commitParser) parse(message string, gitmoji map[string]CommitCategory) (CommitHeader, bool) {
+	subject := strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+	match := p.pattern.FindStringSubmatch(subject)
+	if match == nil {
+		return CommitHeader{}, false
+	}
+
+	header := CommitHeader{Subject: subject, Parser: p.name}
+	for i, group := range p.pattern.SubexpNames() {
+		value := strings.TrimSpace(match[i])
+		switch group {
+		case "type":
+			header.Type = value
+		case "scope":
+			header.Scope = value
+		case "subject":
+			header.Subject = value
+		case "ticket":
+			header.Ticket = value
+		case "breaking":
+			header.Breaking = header.Breaking || value != ""
+		}
+	}
+	header.Breaking = header.Breaking || breakingMarker(message) != ""
+	header.Gitmoji, header.Subject = splitGitmoji(header.Subject, gitmoji)
+	return header, true
+}
+
+// parserRule categorizes commits read by a custom parser from their type
+func (c *Classifier) parserRule(p *commitParser) classifyRule {
+	return func(commit *Commit) RuleResult {
+		result := RuleResult{Rule: p.name}
+		header, ok := p.parse(commit.Message, c.gitmojiTable())
+		switch {
+		case !ok:
+			result.Detail = fmt.Sprintf("pattern %s doesn't match the subject", p.pattern)
+		case header.Breaking:
+			result.Matched, result.Category = true, CategoryBreaking
+			result.Detail = "pattern matches and marks a breaking change"
+		default:
+			category, known := c.typeCategory(header.Type)
+			result.Matched, result.Category = known, category
+			result.Detail = fmt.Sprintf("pattern matches with type '%s'", header.Type)
+			if !known {
+				result.Detail += ", which has no category in type_categories"
+			}
+		}
+		return result
+	}
+}
+
+// pathRule categorizes commits whose changed files match the rule's paths:
+// every file with match "all", at least one with match "any"
+func (c *Classifier) pathRule(rule PathRuleConfig, category CommitCategory) classifyRule {
+	patterns := strings.Join(rule.Paths, ", ")
+	return func(commit *Commit) RuleResult {
+		result := RuleResult{Rule: rule.Name, Category: category}
+		files, err := c.changedFiles(commit)
+		if err != nil {
+			result.Detail = err.Error()
+			return result
+		}
+		if len(files) == 0 {
+			result.Detail = "commit changes no files"
+			return result
+		}
+
+		matching, other := splitFiles(rule.Paths, files)
+		switch {
+		case rule.Match == "any" && len(matching) > 0:
+			result.Matched = true
+			result.Detail = fmt.Sprintf("%s matches %s", matching[0], patterns)
+		case rule.Match == "any" && len(files) == 1:
+			result.Detail = fmt.Sprintf("%s doesn't match %s", files[0], patterns)
+		case rule.Match == "any":
+			result.Detail = fmt.Sprintf("none of %d files match %s", len(files), patterns)
+		case len(other) == 0 && len(files) == 1:
+			result.Matched = true
+			result.Detail = fmt.Sprintf("the only file, %s, matches %s", files[0], patterns)
+		case len(other) == 0:
+			result.Matched = true
+			result.Detail = fmt.Sprintf("all %d files match %s", len(files), patterns)
+		default:
+			result.Detail = fmt.Sprintf("%s doesn't match %s", other[0], patterns)
+		}
+		return result
+	}
+}
+
+// changedFiles lists the files a commit changes, once per commit
+func (c *Classifier) changedFiles(commit *Commit) ([]string, error) {
+	if files, ok := c.files[commit.FullHash]; ok {
+		return files, nil
+	}
+	if c.repo == nil {
+		return nil, fmt.Errorf("no repository to list the changed files")
+	}
+	files, err := ChangedFiles(c.repo, commit.FullHash)
+	if err != nil {
+		return nil, err
+	}
+	c.files[commit.FullHash] = files
+	return files, nil
+}
+
+// splitFiles separates the files matched by any of the patterns from the others
+func splitFiles(patterns, files []string) (matching, other []string) {
+	for _, file := range files {
+		if anyPathMatches(patterns, []string{file}) {
+			matching = append(matching, file)
+		} else {
+			other = append(other, file)
+		}
+	}
+	return matching, other
+}
+
+// typeCategory finds the category for a commit type: type_categories first,
+// then the conventional commit types
+func (c *Classifier) typeCategory(commitType string) (CommitCategory, bool) {
+	if category, ok := c.types[commitType]; ok {
+		return category, true
+	}
+	for name, category := range c.types {
+		if strings.EqualFold(name, commitType) {
+			return category, true
+		}
+	}
+	for _, p := range conventionalPrefixes {
+		if strings.EqualFold(p.prefix, commitType) {
+			return p.category, true
+		}
+	}
+	return "", false
+}
+
+// angularTypes are the other commit types of the Angular convention, which have
+// no category of their own
+var angularTypes = []string{"build", "ci", "style", "revert"}
+
+// IsCommitType reports whether a type is a commit type: one from type_categories,
+// a conventional commit type, or build, ci, style or revert. A nil classifier
+// knows only the built-in types.
+func (c *Classifier) IsCommitType(commitType string) bool {
+	if c == nil {
+		c = defaultClassifier
+	}
+	if _, ok := c.typeCategory(commitType); ok {
+		return true
+	}
+	for _, t := range angularTypes {
+		if strings.EqualFold(t, commitType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Categorize returns the category of the first rule that matches the commit.
+// A nil classifier uses the built-in rules.
+func (c *Classifier) Categorize(commit *Commit) CommitCategory {
+	if c == nil {
+		c = defaultClassifier
+	}
+	for _, rule := range c.rules {
+		if result := rule(commit); result.Matched {
+			return result.Category
+		}
+	}
+	return CategoryOther
+}
+
+// Explain runs every rule against the commit, in order, and returns their results.
+// The first result that matched is the one Categorize uses.
+func (c *Classifier) Explain(commit *Commit) []RuleResult {
+	if c == nil {
+		c = defaultClassifier
+	}
+	var results []RuleResult
+	for _, rule := range c.rules {
+		results = append(results, rule(commit))
+	}
+	return results
+}
+
+// Group groups commits by their category
+func (c *Classifier) Group(commits []*Commit) map[CommitCategory][]*Commit {
+	groups := make(map[CommitCategory][]*Commit)
+	for _, commit := range commits {
+		category := c.Categorize(commit)
+		groups[category] = append(groups[category], commit)
+	}
+	return groups
+}
+
+// breakingRule matches "type!:" subjects and conventional commits with a
+// BREAKING CHANGE footer. An exclamation mark anywhere else, as in "fixup!", doesn't count.
+func breakingRule(gitmoji map[string]CommitCategory) classifyRule {
+	return func(commit *Commit) RuleResult {
+		result := RuleResult{Rule: "breaking marker", Category: CategoryBreaking}
+		header := parseCommitHeader(commit.Message, gitmoji)
+		marker := breakingMarker(commit.Message)
+		switch {
+		case header.Breaking && marker != "":
+			result.Matched = true
+			result.Detail = fmt.Sprintf("footer starts with '%s'", marker)
+		case header.Breaking:
+			result.Matched = true
+			result.Detail = fmt.Sprintf("subject starts with '%s!:'", header.Type)
+		default:
+			result.Detail = "no type!: marker or " + strings.Join(breakingMarkers, ", ") + " footer"
+		}
+		return result
+	}
+}
+
+// conventionalPrefixRule matches "type:", "type(scope):" and "type!:" messages
+func conventionalPrefixRule(commit *Commit) RuleResult {
+	result := RuleResult{Rule: "conventional prefix"}
+	var types []string
+	for _, p := range conventionalPrefixes {
+		if hasPrefix(commit.Message, p.prefix) {
+			result.Matched = true
+			result.Category = p.category
+			result.Detail = fmt.Sprintf("message starts with %q", commit.Message[:len(p.prefix)+1])
+			return result
+		}
+		types = append(types, p.prefix)
+	}
+	result.Detail = "message doesn't start with " + strings.Join(types, ", ")
+	return result
+}
+
+// gitmojiRule matches messages starting with a gitmoji, before or after a conventional prefix
+func gitmojiRule(table map[string]CommitCategory) classifyRule {
+	return func(commit *Commit) RuleResult {
+		result := RuleResult{Rule: "gitmoji"}
+		gitmoji := parseCommitHeader(commit.Message, table).Gitmoji
+		if gitmoji == "" {
+			result.Detail = "subject doesn't start with a gitmoji"
+			return result
+		}
+		result.Category, result.Matched = table[gitmojiKey(gitmoji)]
+		result.Detail = fmt.Sprintf("subject starts with %s", gitmoji)
+		if !result.Matched {
+			result.Detail += ", which has no category"
+		}
+		return result
+	}
+}
+
+// keywordRule matches messages containing any of the keywords, ignoring case.
+// Keywords match anywhere, including inside longer words.
+func keywordRule(name string, category CommitCategory, keywords []string) classifyRule {
+	return func(commit *Commit) RuleResult {
+		result := RuleResult{Rule: name, Category: category}
+		msg := asciiLower(commit.Message)
+		for _, keyword := range keywords {
+			if at := strings.Index(msg, asciiLower(keyword)); at >= 0 {
+				result.Matched = true
+				result.Detail = fmt.Sprintf("keyword '%s' found in '%s'", keyword, wordAt(commit.Message, at, len(keyword)))
+				return result
+			}
+		}
+		result.Detail = "no keyword of " + strings.Join(keywords, ", ")
+		return result
+	}
+}
+
+// fallbackRule puts commits no other rule matched in Other
+func fallbackRule(commit *Commit) RuleResult {
+	return RuleResult{Rule: "fallback", Matched: true, Category: CategoryOther, Detail: "no other rule matched"}
+}
+
+// asciiLower lowercases ASCII letters only, so byte offsets stay the same as in s
+func asciiLower(s string) string {
+	lower := []byte(s)
+	for i, c := range lower {
+		if c >= 'A' && c <= 'Z' {
+			lower[i] = c + 'a' - 'A'
+		}
+	}
+	return string(lower)
+}
+
+// wordAt returns the word around text[at:at+length], without surrounding punctuation
+func wordAt(text string, at, length int) string {
+	start := strings.LastIndexAny(text[:at], " \t\n") + 1
+	end := len(text)
+	if i := strings.IndexAny(text[at+length:], " \t\n"); i >= 0 {
+		end = at + length + i
+	}
+	word := strings.Trim(text[start:end], ".,;:()[]{}\"'`")
+	if word == "" {
+		return text[start:end]
+	}
+	return word
+}
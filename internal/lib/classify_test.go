@@ -0,0 +1,94 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitFiles writes the files into the repository's worktree and commits them
+func commitFiles(t *testing.T, repo *git.Repository, message string, files map[string][]byte) *Commit {
+	t.Helper()
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := worktree.Filesystem.Root()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	signature := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Unix(1700000000, 0)}
+	hash, err := worktree.Commit(message, &git.CommitOptions{Author: signature, Committer: signature})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newCommit(commit)
+}
+
+func TestPathRuleSeesBinaryFiles(t *testing.T) {
+	repo, err := git.PlainInit(t.TempDir(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, repo, "Initial import", map[string][]byte{"README.md": []byte("# Project\n")})
+	binary := []byte{0x89, 'P', 'N', 'G', 0x00, 0x01, 0x02, 0x00}
+
+	config := &Config{}
+	config.PathRules.Rules = []PathRuleConfig{{Name: "docs", Paths: []string{"docs/"}, Category: "documentation"}}
+	classifier, err := NewClassifier(repo, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		files map[string][]byte
+		want  CommitCategory
+	}{
+		{"only docs", map[string][]byte{"docs/guide.md": []byte("Guide\n")}, CategoryDocs},
+		{"binary docs", map[string][]byte{"docs/diagram.png": binary}, CategoryDocs},
+		{"docs and a binary elsewhere", map[string][]byte{"docs/intro.md": []byte("Intro\n"), "assets/logo.png": binary}, CategoryOther},
+		{"docs and an empty file elsewhere", map[string][]byte{"docs/faq.md": []byte("FAQ\n"), "assets/.keep": nil}, CategoryOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commit := commitFiles(t, repo, "Tweak "+tt.name, tt.files)
+			if got := classifier.Categorize(commit); got != tt.want {
+				t.Errorf("Categorize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangedFilesListsBinaryFiles(t *testing.T) {
+	repo, err := git.PlainInit(t.TempDir(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := commitFiles(t, repo, "Initial import", map[string][]byte{"logo.png": {0x00, 0x01}})
+	files, err := ChangedFiles(repo, root.FullHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0] != "logo.png" {
+		t.Errorf("ChangedFiles() = %v, want [logo.png]", files)
+	}
+}
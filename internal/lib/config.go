@@ -3,6 +3,8 @@ package lib
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -17,11 +19,27 @@ type Config struct {
 	Git struct {
 		RepositoryPath string `yaml:"repository_path"`
 		DefaultBranch  string `yaml:"default_branch"`
+		Remote         string `yaml:"remote"`
 	} `yaml:"git"`
 
+	Forge struct {
+		Type           string            `yaml:"type"`
+		URL            string            `yaml:"url"`
+		Hosts          map[string]string `yaml:"hosts"`
+		CommitURL      string            `yaml:"commit_url"`
+		CompareURL     string            `yaml:"compare_url"`
+		TagURL         string            `yaml:"tag_url"`
+		PullRequestURL string            `yaml:"pull_request_url"`
+	} `yaml:"forge"`
+
 	Output struct {
-		Format   string `yaml:"format"`
-		Filename string `yaml:"filename"`
+		Format   string       `yaml:"format"`
+		Filename string       `yaml:"filename"`
+		Locale   string       `yaml:"locale"`   // e.g. "de"; empty for the default English output
+		Catalogs string       `yaml:"catalogs"` // directory with custom <locale>.yaml catalogs
+		Entries  EntryOptions `yaml:",inline"`
+		HTML     HTMLOptions  `yaml:"html"`
+		Feed     FeedOptions  `yaml:"feed"`
 	} `yaml:"output"`
 
 	AI struct {
@@ -30,7 +48,138 @@ type Config struct {
 		Model    string `yaml:"model"`
 	} `yaml:"ai"`
 
+	Contributors struct {
+		Enabled   bool     `yaml:"enabled"`
+		Mailmap   string   `yaml:"mailmap"`
+		Exclude   []string `yaml:"exclude"`
+		FirstTime bool     `yaml:"highlight_first_time"`
+	} `yaml:"contributors"`
+
+	Versioning struct {
+		Strategy     string   `yaml:"strategy"`
+		TagPrefix    string   `yaml:"tag_prefix"`
+		Scheme       string   `yaml:"scheme"`
+		CalverFormat string   `yaml:"calver_format"`
+		Channels     []string `yaml:"channels"`
+	} `yaml:"versioning"`
+
+	Release struct {
+		CommitMessage string `yaml:"commit_message"`
+	} `yaml:"release"`
+
+	Packaging PackagingOptions `yaml:"packaging"`
+
+	VersionTargets []VersionTarget `yaml:"version_targets"`
+
 	Categories []string `yaml:"categories"`
+
+	Parsers        []ParserConfig    `yaml:"parsers"`         // custom commit formats, tried before the built-in rules
+	TypeCategories map[string]string `yaml:"type_categories"` // commit type captured by a parser -> category key
+
+	GitmojiCategories map[string]string `yaml:"gitmoji_categories"` // gitmoji shortcode or emoji -> category key, extending the built-in table
+
+	Exclude []ExcludeConfig `yaml:"exclude"` // commits left out of the changelog
+
+	PathRules struct {
+		Precedence string           `yaml:"precedence"` // first, before_keywords (default) or last
+		Rules      []PathRuleConfig `yaml:"rules"`
+	} `yaml:"path_rules"`
+}
+
+// ExcludeConfig leaves commits out of the changelog. Every field that is set must
+// match; a commit is excluded when any rule matches it.
+type ExcludeConfig struct {
+	Name    string   `yaml:"name"`
+	Message string   `yaml:"message"` // regular expression matched against the whole message
+	Type    string   `yaml:"type"`    // commit type, e.g. "chore"
+	Scope   string   `yaml:"scope"`   // commit scope, e.g. "deps"
+	Author  string   `yaml:"author"`  // regular expression matched against "Name <email>", ignoring case
+	Trailer string   `yaml:"trailer"` // Key or Key=value
+	Paths   []string `yaml:"paths"`   // the commit changes only files matching these
+}
+
+// PathRuleConfig categorizes commits by the files they change
+type PathRuleConfig struct {
+	Name     string   `yaml:"name"`
+	Paths    []string `yaml:"paths"`    // files, directories or globs such as "*_test.go"
+	Category string   `yaml:"category"` // category key, e.g. "documentation"
+	Match    string   `yaml:"match"`    // all (default): every changed file matches; any: one is enough
+}
+
+// ParserConfig describes a custom commit format as a regular expression with
+// named groups: type, scope, subject, ticket and breaking
+type ParserConfig struct {
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"` // matched against the subject line
+}
+
+// EntryOptions control how entries and their sections are written in every output format
+type EntryOptions struct {
+	Body            BodyOptions `yaml:"body"`             // how commit bodies are shown, per category
+	CodeSpans       bool        `yaml:"code_spans"`       // keep `code` in commit messages as inline code instead of escaping it
+	GitmojiHeadings bool        `yaml:"gitmoji_headings"` // start category headings with their gitmoji, e.g. "✨ Features"
+
+	// Keep a Changelog section per commit type or category key, over the defaults;
+	// "" leaves those entries out
+	KeepAChangelog map[string]string `yaml:"keepachangelog"`
+}
+
+// KeepAChangelogSections are the Keep a Changelog section titles, in the order the spec lists them
+var KeepAChangelogSections = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+// defaultKeepAChangelogSections map commit types and category keys to Keep a
+// Changelog sections. Documentation, tests and chores aren't notable changes.
+var defaultKeepAChangelogSections = map[string]string{
+	"breaking":    "Changed",
+	"features":    "Added",
+	"fixes":       "Fixed",
+	"performance": "Changed",
+	"refactoring": "Changed",
+	"other":       "Changed",
+	"deprecate":   "Deprecated",
+	"revert":      "Removed",
+	"security":    "Security",
+}
+
+// KeepAChangelogSection returns the Keep a Changelog section of an entry with the
+// given category and commit type, or "" to leave it out. Breaking changes are
+// looked up first, then the commit type, then the category.
+func (o EntryOptions) KeepAChangelogSection(category CommitCategory, commitType string) string {
+	keys := []string{strings.ToLower(commitType), category.Key()}
+	if category == CategoryBreaking {
+		keys = append([]string{"breaking"}, keys...)
+	}
+	for _, key := range keys {
+		if title, ok := o.KeepAChangelog[key]; ok {
+			return title
+		}
+		if title, ok := defaultKeepAChangelogSections[key]; ok {
+			return title
+		}
+	}
+	return ""
+}
+
+// HTMLOptions control the html output format
+type HTMLOptions struct {
+	Fragment   bool   `yaml:"fragment"`   // render only the changelog markup, for embedding in another page
+	Stylesheet string `yaml:"stylesheet"` // CSS file to inline, or a URL to link, instead of the built-in theme
+}
+
+// FeedOptions control the rss and atom output formats
+type FeedOptions struct {
+	Title       string `yaml:"title"`       // defaults to "<project> releases"
+	Description string `yaml:"description"` // RSS channel description
+	Link        string `yaml:"link"`        // URL of the hosted changelog; items link to its version anchors
+}
+
+// PackagingOptions control the debian and rpm output formats
+type PackagingOptions struct {
+	Name         string `yaml:"name"`         // package name; defaults to the project name
+	Maintainer   string `yaml:"maintainer"`   // "Full Name <email>"
+	Distribution string `yaml:"distribution"` // Debian distribution, e.g. "unstable"
+	Urgency      string `yaml:"urgency"`      // Debian urgency, e.g. "medium"
+	Revision     string `yaml:"revision"`     // package revision appended to the version, e.g. "1"
 }
 
 func LoadConfig(filename string) (*Config, error) {
@@ -43,12 +192,21 @@ func LoadConfig(filename string) (*Config, error) {
 	//Create a Config struct instance to hold the data
 	var config Config
 
+	//Defaults for settings that may legitimately be set to an empty value
+	config.Versioning.TagPrefix = "v"
+
 	//Unmarshal YAML data into the Config struct
 	err = yaml.Unmarshal(data, &config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
+	for key, title := range config.Output.Entries.KeepAChangelog {
+		if title != "" && !slices.Contains(KeepAChangelogSections, title) {
+			return nil, fmt.Errorf("output.keepachangelog.%s: %q is not one of %s", key, title, strings.Join(KeepAChangelogSections, ", "))
+		}
+	}
+
 	return &config, nil
 }
 
@@ -57,6 +215,8 @@ func PrintConfig(config *Config) {
 	fmt.Println()
 	fmt.Printf("  Project: %s (v%s)\n", config.Project.Name, config.Project.Version)
 	fmt.Printf("  Repository: %s\n", config.Git.RepositoryPath)
+	fmt.Printf("  Remote: %s\n", config.Git.Remote)
+	fmt.Printf("  Versioning: %s (tags %s*)\n", config.Versioning.Strategy, config.Versioning.TagPrefix)
 	fmt.Printf("  Output: %s (%s)\n", config.Output.Filename, config.Output.Format)
 	fmt.Printf("  AI: %v (%s)\n", config.AI.Enabled, config.AI.Provider)
 	fmt.Printf("  Categories: %v\n", config.Categories)
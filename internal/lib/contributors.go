@@ -0,0 +1,258 @@
+package lib
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Contributor is a person who authored or co-authored commits in a release
+type Contributor struct {
+	Name      string `json:"name"`
+	Email     string `json:"email,omitempty"`
+	Commits   int    `json:"commits,omitempty"`
+	FirstTime bool   `json:"first_time,omitempty"` // first commit in the repository is part of this release
+}
+
+// mailmapEntry is one line of a .mailmap file
+type mailmapEntry struct {
+	properName  string
+	properEmail string
+	commitName  string
+	commitEmail string
+}
+
+// Mailmap maps commit identities to canonical names and emails
+type Mailmap struct {
+	entries []mailmapEntry
+}
+
+// mailmapEmail matches the <email> parts of a mailmap line
+var mailmapEmail = regexp.MustCompile(`<([^>]*)>`)
+
+// LoadMailmap reads a .mailmap file. A missing file gives an empty mailmap.
+func LoadMailmap(path string) (*Mailmap, error) {
+	mailmap := &Mailmap{}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return mailmap, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mailmap: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[:i]
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		// Split the line into names and emails:
+		//   Proper Name <proper@email> Commit Name <commit@email>
+		locs := mailmapEmail.FindAllStringSubmatchIndex(line, -1)
+		if len(locs) == 0 {
+			continue
+		}
+
+		entry := mailmapEntry{
+			properName:  strings.TrimSpace(line[:locs[0][0]]),
+			properEmail: line[locs[0][2]:locs[0][3]],
+		}
+		if len(locs) > 1 {
+			entry.commitName = strings.TrimSpace(line[locs[0][1]:locs[1][0]])
+			entry.commitEmail = line[locs[1][2]:locs[1][3]]
+		} else {
+			// "Proper Name <email>" only fixes the name for that email
+			entry.commitEmail = entry.properEmail
+			entry.properEmail = ""
+		}
+		mailmap.entries = append(mailmap.entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mailmap: %w", err)
+	}
+
+	return mailmap, nil
+}
+
+// Resolve returns the canonical name and email for a commit identity
+func (m *Mailmap) Resolve(name, email string) (string, string) {
+	if m == nil {
+		return name, email
+	}
+
+	// Entries matching both name and email win over email-only entries
+	var match *mailmapEntry
+	for i := range m.entries {
+		entry := &m.entries[i]
+		if !strings.EqualFold(entry.commitEmail, email) {
+			continue
+		}
+		if entry.commitName != "" {
+			if entry.commitName == name {
+				match = entry
+				break
+			}
+			continue
+		}
+		if match == nil {
+			match = entry
+		}
+	}
+
+	if match == nil {
+		return name, email
+	}
+	if match.properName != "" {
+		name = match.properName
+	}
+	if match.properEmail != "" {
+		email = match.properEmail
+	}
+	return name, email
+}
+
+// ContributorOptions controls how contributors are collected
+type ContributorOptions struct {
+	Mailmap   *Mailmap
+	Exclude   []string // regular expressions matched against "Name <email>"
+	FirstTime bool     // detect people whose first commit is in the release
+}
+
+// coAuthor matches the "Name <email>" value of a Co-authored-by trailer
+var coAuthor = regexp.MustCompile(`^(.*?)\s*<([^>]+)>$`)
+
+// commitIdentities returns the author and co-authors of a commit
+func commitIdentities(name, email, message string) [][2]string {
+	identities := [][2]string{{name, email}}
+	for _, value := range TrailerValues(ParseTrailers(message), "Co-authored-by") {
+		if match := coAuthor.FindStringSubmatch(value); match != nil {
+			identities = append(identities, [2]string{match[1], match[2]})
+		}
+	}
+	return identities
+}
+
+// identityKey is the key used to merge identities after mailmap resolution
+func identityKey(name, email string) string {
+	if email != "" {
+		return strings.ToLower(email)
+	}
+	return strings.ToLower(name)
+}
+
+// CollectContributors lists the people who contributed to the given commits
+func CollectContributors(repo *git.Repository, commits []*Commit, opts ContributorOptions) ([]*Contributor, error) {
+	var excludes []*regexp.Regexp
+	for _, pattern := range opts.Exclude {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid contributor exclude pattern %q: %w", pattern, err)
+		}
+		excludes = append(excludes, re)
+	}
+
+	contributors := make(map[string]*Contributor)
+	inRelease := make(map[string]bool)
+
+	for _, commit := range commits {
+		inRelease[commit.FullHash] = true
+
+		for _, identity := range commitIdentities(commit.Author, commit.Email, commit.Message) {
+			name, email := opts.Mailmap.Resolve(identity[0], identity[1])
+			if isExcludedContributor(name, email, excludes) {
+				continue
+			}
+
+			key := identityKey(name, email)
+			contributor, exists := contributors[key]
+			if !exists {
+				contributor = &Contributor{Name: name, Email: email}
+				contributors[key] = contributor
+			}
+			contributor.Commits++
+		}
+	}
+
+	if opts.FirstTime && len(contributors) > 0 {
+		if err := markFirstTimeContributors(repo, contributors, inRelease, opts.Mailmap); err != nil {
+			return nil, err
+		}
+	}
+
+	// Most active contributors first, then alphabetical
+	var list []*Contributor
+	for _, contributor := range contributors {
+		list = append(list, contributor)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Commits != list[j].Commits {
+			return list[i].Commits > list[j].Commits
+		}
+		return strings.ToLower(list[i].Name) < strings.ToLower(list[j].Name)
+	})
+
+	return list, nil
+}
+
+// isExcludedContributor checks a contributor against the bot patterns
+func isExcludedContributor(name, email string, excludes []*regexp.Regexp) bool {
+	identity := fmt.Sprintf("%s <%s>", name, email)
+	for _, re := range excludes {
+		if re.MatchString(identity) {
+			return true
+		}
+	}
+	return false
+}
+
+// markFirstTimeContributors walks the full history to find each person's first commit
+func markFirstTimeContributors(repo *git.Repository, contributors map[string]*Contributor, inRelease map[string]bool, mailmap *Mailmap) error {
+	ref, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	commitIter, err := repo.Log(&git.LogOptions{From: ref.Hash(), Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return fmt.Errorf("failed to get log: %w", err)
+	}
+
+	// The log is newest first, so the last commit seen for a person is their first
+	firstCommit := make(map[string]*object.Commit)
+	err = commitIter.ForEach(func(c *object.Commit) error {
+		for _, identity := range commitIdentities(c.Author.Name, c.Author.Email, c.Message) {
+			name, email := mailmap.Resolve(identity[0], identity[1])
+			key := identityKey(name, email)
+			if _, tracked := contributors[key]; !tracked {
+				continue
+			}
+			if first, seen := firstCommit[key]; !seen || !c.Author.When.After(first.Author.When) {
+				firstCommit[key] = c
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error iterating commits: %w", err)
+	}
+
+	for key, contributor := range contributors {
+		if first, ok := firstCommit[key]; ok {
+			contributor.FirstTime = inRelease[first.Hash.String()]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package lib
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CommitHeader is the parsed subject line of a commit
+type CommitHeader struct {
+	Type     string // conventional commit type (feat, fix, ...); empty if not conventional
+	Scope    string
+	Breaking bool
+	Subject  string // subject without the type and scope prefix or gitmoji
+	Gitmoji  string // leading gitmoji shortcode or emoji, e.g. ":sparkles:"
+	Ticket   string // issue key captured by a custom parser, e.g. "ABC-123"
+	Parser   string // name of the custom parser that read the header; empty for conventional commits
+}
+
+// conventionalHeader matches "type(scope)!: subject"
+var conventionalHeader = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^)]*)\))?(!)?:\s*(.*)$`)
+
+// ParseCommitHeader parses the first line of a commit message, with the built-in gitmoji
+func ParseCommitHeader(message string) CommitHeader {
+	return parseCommitHeader(message, builtinGitmojiTable)
+}
+
+// parseCommitHeader parses a header, splitting off the gitmoji in table
+func parseCommitHeader(message string, table map[string]CommitCategory) CommitHeader {
+	subject := strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+
+	// A gitmoji may come before the type, "✨ feat: ...", or after it, "feat: ✨ ..."
+	gitmoji, subject := splitGitmoji(subject, table)
+	match := conventionalHeader.FindStringSubmatch(subject)
+	if match == nil {
+		return CommitHeader{Subject: subject, Gitmoji: gitmoji}
+	}
+
+	header := CommitHeader{
+		Type:     strings.ToLower(match[1]),
+		Scope:    match[2],
+		Breaking: match[3] == "!" || breakingMarker(message) != "",
+		Subject:  match[4],
+		Gitmoji:  gitmoji,
+	}
+	if header.Gitmoji == "" {
+		header.Gitmoji, header.Subject = splitGitmoji(header.Subject, table)
+	}
+	return header
+}
+
+// breakingMarkers start a footer line that marks a breaking change
+var breakingMarkers = []string{"BREAKING CHANGE:", "BREAKING-CHANGE:"}
+
+// breakingMarker returns the breaking change marker that starts a footer line of
+// a message, or "" if it has none. The footer is everything after the first blank
+// line, so the phrase in the subject or in the middle of a sentence doesn't count.
+func breakingMarker(message string) string {
+	_, footer, found := strings.Cut(strings.ReplaceAll(message, "\r\n", "\n"), "\n\n")
+	if !found {
+		return ""
+	}
+	for _, line := range strings.Split(footer, "\n") {
+		for _, marker := range breakingMarkers {
+			if strings.HasPrefix(line, marker) {
+				return marker
+			}
+		}
+	}
+	return ""
+}
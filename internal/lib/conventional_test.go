@@ -0,0 +1,38 @@
+package lib
+
+import "testing"
+
+func TestBreakingChangeNeedsMarkerOrFooter(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    bool
+	}{
+		{"type marker", "feat!: drop the v1 API", true},
+		{"footer", "feat: new API\n\nBREAKING CHANGE: the v1 API is gone", true},
+		{"hyphenated footer", "fix: config keys\n\nRefs #12\nBREAKING-CHANGE: keys are renamed", true},
+		{"phrase in the subject", "docs: explain the BREAKING CHANGE footer", false},
+		{"phrase in the body", "docs: document footers\n\nA BREAKING CHANGE: footer bumps major.", false},
+		{"subject starting with the footer", "BREAKING CHANGE: everything", false},
+		{"old marker", "fix: new API\n\nBREAKING: the v1 API is gone", false},
+		{"footer without a conventional header", "Rework the API\n\nBREAKING CHANGE: the v1 API is gone", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commit := &Commit{Message: tt.message}
+			if got := ParseCommitHeader(tt.message).Breaking; got != tt.want {
+				t.Errorf("Breaking = %v, want %v", got, tt.want)
+			}
+			if got := defaultClassifier.Categorize(commit) == CategoryBreaking; got != tt.want {
+				t.Errorf("Categorize() breaking = %v, want %v", got, tt.want)
+			}
+			want := BumpPatch
+			if tt.want {
+				want = BumpMajor
+			}
+			if got := DetermineBump(&Version{Major: 1}, []*Commit{commit}, nil); got != want {
+				t.Errorf("DetermineBump() = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// editFileHelp is the comment block at the top of an editable changelog
+const editFileHelp = `# Edit the changelog entries below, then save and close the editor.
+#
+# Each entry is one line:  <hash> <category> <text>
+# Categories: %s
+#
+# Reorder lines to reorder entries, delete a line to drop an entry,
+# or use "-" as the hash to add an entry that has no commit.
+# Release headings ("## ...") can't be added, removed or renamed.
+# Lines starting with "#" are ignored. Remove everything to abort.
+`
+
+// EditLineError is a problem on one line of an edited changelog
+type EditLineError struct {
+	Line    int
+	Message string
+}
+
+func (e *EditLineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// EditErrors collects every problem found in an edited changelog
+type EditErrors []*EditLineError
+
+func (e EditErrors) Error() string {
+	var lines []string
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatEditableChangelog writes the changelog as a plain text file for editing by hand
+func FormatEditableChangelog(changelog *Changelog) string {
+	var keys []string
+	for _, category := range CategoryOrder {
+		keys = append(keys, category.Key())
+	}
+
+	out := fmt.Sprintf(editFileHelp, strings.Join(keys, ", "))
+	for _, release := range changelog.Releases {
+		out += "\n" + editFileHeading(release) + "\n"
+		for _, section := range release.Sections {
+			for _, entry := range section.Entries {
+				hash := entry.Hash
+				if hash == "" {
+					hash = "-"
+				}
+				out += fmt.Sprintf("%s %s %s\n", hash, section.Category.Key(), entry.Text)
+			}
+		}
+	}
+	return out
+}
+
+// editFileHeading is the heading of a release in an editable changelog
+func editFileHeading(release *Release) string {
+	if release.IsUnreleased() {
+		return "## Unreleased"
+	}
+	return "## " + release.Version
+}
+
+// ApplyEditedChangelog reads an edited file back into the changelog it was written from.
+// Nothing is changed unless the whole file is valid. It returns false if the file
+// was emptied to abort.
+func ApplyEditedChangelog(changelog *Changelog, content string) (bool, error) {
+	var errs EditErrors
+	sections := make([][]*Section, len(changelog.Releases))
+	current := -1
+	seen := make(map[string]bool)
+	empty := true
+
+	for i, line := range strings.Split(content, "\n") {
+		number := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || (strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "## ")) {
+			continue
+		}
+		empty = false
+
+		if strings.HasPrefix(line, "## ") {
+			current++
+			if current >= len(changelog.Releases) {
+				errs = append(errs, &EditLineError{number, fmt.Sprintf("unexpected release heading %q", line)})
+				continue
+			}
+			if expected := editFileHeading(changelog.Releases[current]); line != expected {
+				errs = append(errs, &EditLineError{number, fmt.Sprintf("release heading %q was changed, expected %q", line, expected)})
+			}
+			continue
+		}
+		if current < 0 || current >= len(changelog.Releases) {
+			errs = append(errs, &EditLineError{number, "entry outside of a release"})
+			continue
+		}
+
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) < 3 || strings.TrimSpace(fields[2]) == "" {
+			errs = append(errs, &EditLineError{number, "expected <hash> <category> <text>"})
+			continue
+		}
+
+		category, ok := CategoryForKey(fields[1])
+		if !ok {
+			errs = append(errs, &EditLineError{number, fmt.Sprintf("unknown category %q", fields[1])})
+			continue
+		}
+
+		release := changelog.Releases[current]
+		entry := &Entry{Text: strings.TrimSpace(fields[2])}
+		title := sectionTitle(release, category)
+		if hash := fields[0]; hash != "-" {
+			original, from := findEntry(release, hash)
+			if original == nil {
+				heading := strings.TrimPrefix(editFileHeading(release), "## ")
+				errs = append(errs, &EditLineError{number, fmt.Sprintf("no commit %s in %s", hash, heading)})
+				continue
+			}
+			if seen[hash] {
+				errs = append(errs, &EditLineError{number, fmt.Sprintf("commit %s is listed twice", hash)})
+				continue
+			}
+			seen[hash] = true
+			// Keep the commit details, with the text as edited
+			copied := *original
+			copied.Text = entry.Text
+			entry = &copied
+
+			// An entry left in its category stays under its section's title
+			if from.Category == category {
+				title = from.Title
+			}
+		}
+
+		sections[current] = addToSection(sections[current], category, title, entry)
+	}
+
+	if empty {
+		return false, nil
+	}
+	if current < len(changelog.Releases)-1 && len(errs) == 0 {
+		missing := editFileHeading(changelog.Releases[current+1])
+		errs = append(errs, &EditLineError{len(strings.Split(content, "\n")), fmt.Sprintf("release heading %q is missing", missing)})
+	}
+	if len(errs) > 0 {
+		return false, errs
+	}
+
+	for i, release := range changelog.Releases {
+		sortSections(sections[i])
+		release.Sections = sections[i]
+	}
+	return true, nil
+}
+
+// findEntry finds the entry of a release by its commit hash, and the section it's in
+func findEntry(release *Release, hash string) (*Entry, *Section) {
+	for _, section := range release.Sections {
+		for _, entry := range section.Entries {
+			if entry.Hash == hash {
+				return entry, section
+			}
+		}
+	}
+	return nil, nil
+}
+
+// sectionTitle is the title of the release's first section of a category, such as
+// a heading of a parsed changelog, or "" for the default title
+func sectionTitle(release *Release, category CommitCategory) string {
+	for _, section := range release.Sections {
+		if section.Category == category {
+			return section.Title
+		}
+	}
+	return ""
+}
+
+// addToSection appends an entry to the section with its category and title,
+// creating it if needed
+func addToSection(sections []*Section, category CommitCategory, title string, entry *Entry) []*Section {
+	for _, section := range sections {
+		if section.Category == category && section.Title == title {
+			section.Entries = append(section.Entries, entry)
+			return sections
+		}
+	}
+	return append(sections, &Section{Category: category, Title: title, Entries: []*Entry{entry}})
+}
+
+// sortSections puts sections in the usual category order
+func sortSections(sections []*Section) {
+	rank := make(map[CommitCategory]int)
+	for i, category := range CategoryOrder {
+		rank[category] = i
+	}
+	sort.SliceStable(sections, func(i, j int) bool {
+		return rank[sections[i].Category] < rank[sections[j].Category]
+	})
+}
@@ -0,0 +1,36 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyEditedChangelogKeepsSectionTitles(t *testing.T) {
+	changelog := &Changelog{Releases: []*Release{{
+		Version: "1.2.0",
+		Sections: []*Section{
+			{Category: CategoryFeature, Title: "Added", Entries: []*Entry{{Hash: "aaaaaaa", Text: "Dark mode"}}},
+			{Category: CategoryOther, Title: "Changed", Entries: []*Entry{{Hash: "bbbbbbb", Text: "New defaults"}}},
+			{Category: CategoryOther, Title: "Removed", Entries: []*Entry{{Hash: "ccccccc", Text: "Old flag"}}},
+		},
+	}}}
+
+	edited := strings.Replace(FormatEditableChangelog(changelog), "aaaaaaa features Dark mode", "aaaaaaa features Dark mode for everyone", 1)
+	edited = strings.Replace(edited, "ccccccc other Old flag", "ccccccc fixes Old flag", 1)
+	ok, err := ApplyEditedChangelog(changelog, edited)
+	if !ok || err != nil {
+		t.Fatalf("ApplyEditedChangelog() = %v, %v", ok, err)
+	}
+
+	var got []string
+	for _, section := range changelog.Releases[0].Sections {
+		for _, entry := range section.Entries {
+			got = append(got, section.Title+": "+entry.Text)
+		}
+	}
+	// A moved entry takes the default title of its new category
+	want := []string{"Added: Dark mode for everyone", ": Old flag", "Changed: New defaults"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("sections = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,183 @@
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ExcludedCommit is a commit left out of the changelog and the rule that excluded it
+type ExcludedCommit struct {
+	Commit *Commit
+	Rule   string
+}
+
+// exclusionRule is a compiled ExcludeConfig
+type exclusionRule struct {
+	name       string
+	message    *regexp.Regexp
+	commitType string
+	scope      string
+	author     *regexp.Regexp
+	trailer    *TrailerFilter
+	paths      []string
+}
+
+// newExclusionRule compiles an exclusion from the config; index numbers unnamed rules
+func newExclusionRule(config ExcludeConfig, index int) (*exclusionRule, error) {
+	rule := &exclusionRule{
+		name:       config.Name,
+		commitType: config.Type,
+		scope:      config.Scope,
+		paths:      config.Paths,
+	}
+	if rule.name == "" {
+		rule.name = fmt.Sprintf("exclusion %d", index+1)
+	}
+
+	var err error
+	if config.Message != "" {
+		if rule.message, err = regexp.Compile(config.Message); err != nil {
+			return nil, fmt.Errorf("invalid message pattern for %s: %w", rule.name, err)
+		}
+	}
+	if config.Author != "" {
+		if rule.author, err = regexp.Compile("(?i)" + config.Author); err != nil {
+			return nil, fmt.Errorf("invalid author pattern for %s: %w", rule.name, err)
+		}
+	}
+	if config.Trailer != "" {
+		trailer, err := ParseTrailerFilter(config.Trailer)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rule.name, err)
+		}
+		rule.trailer = &trailer
+	}
+
+	if rule.message == nil && rule.commitType == "" && rule.scope == "" &&
+		rule.author == nil && rule.trailer == nil && len(rule.paths) == 0 {
+		return nil, fmt.Errorf("%s has nothing to match; set message, type, scope, author, trailer or paths", rule.name)
+	}
+	return rule, nil
+}
+
+// Exclude splits commits into those that go into the changelog and those an
+// exclusion rule leaves out, keeping their order
+func (c *Classifier) Exclude(commits []*Commit) ([]*Commit, []ExcludedCommit) {
+	if c == nil || len(c.exclude) == 0 {
+		return commits, nil
+	}
+	var kept []*Commit
+	var excluded []ExcludedCommit
+	for _, commit := range commits {
+		if rule, ok := c.Excluded(commit); ok {
+			excluded = append(excluded, ExcludedCommit{Commit: commit, Rule: rule})
+		} else {
+			kept = append(kept, commit)
+		}
+	}
+	return kept, excluded
+}
+
+// Excluded returns the name of the first exclusion rule that matches the commit
+func (c *Classifier) Excluded(commit *Commit) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	for _, rule := range c.exclude {
+		if result := c.checkExclusion(rule, commit); result.Matched {
+			return rule.name, true
+		}
+	}
+	return "", false
+}
+
+// ExplainExclusions runs every exclusion rule against the commit, in order
+func (c *Classifier) ExplainExclusions(commit *Commit) []RuleResult {
+	if c == nil {
+		return nil
+	}
+	var results []RuleResult
+	for _, rule := range c.exclude {
+		results = append(results, c.checkExclusion(rule, commit))
+	}
+	return results
+}
+
+// checkExclusion tests each condition of a rule in turn. The detail names the
+// first condition that failed, or every condition when all of them matched.
+func (c *Classifier) checkExclusion(rule *exclusionRule, commit *Commit) RuleResult {
+	result := RuleResult{Rule: rule.name}
+	header := c.Parse(commit.Message)
+	var found []string
+
+	if rule.message != nil {
+		if !rule.message.MatchString(commit.Message) {
+			result.Detail = fmt.Sprintf("message doesn't match %s", rule.message)
+			return result
+		}
+		found = append(found, fmt.Sprintf("message matches %s", rule.message))
+	}
+	if rule.commitType != "" {
+		if !strings.EqualFold(header.Type, rule.commitType) {
+			result.Detail = fmt.Sprintf("type %s isn't '%s'", quoteOrNone(header.Type), rule.commitType)
+			return result
+		}
+		found = append(found, fmt.Sprintf("type is '%s'", header.Type))
+	}
+	if rule.scope != "" {
+		if !strings.EqualFold(header.Scope, rule.scope) {
+			result.Detail = fmt.Sprintf("scope %s isn't '%s'", quoteOrNone(header.Scope), rule.scope)
+			return result
+		}
+		found = append(found, fmt.Sprintf("scope is '%s'", header.Scope))
+	}
+	if rule.author != nil {
+		author := fmt.Sprintf("%s <%s>", commit.Author, commit.Email)
+		if !rule.author.MatchString(author) {
+			result.Detail = fmt.Sprintf("author '%s' doesn't match %s", author, strings.TrimPrefix(rule.author.String(), "(?i)"))
+			return result
+		}
+		found = append(found, fmt.Sprintf("author '%s' matches", author))
+	}
+	if rule.trailer != nil {
+		want := rule.trailer.Key
+		if rule.trailer.Value != "" {
+			want += "=" + rule.trailer.Value
+		}
+		if !hasTrailer(ParseTrailers(commit.Message), *rule.trailer) {
+			result.Detail = fmt.Sprintf("no trailer %s", want)
+			return result
+		}
+		found = append(found, fmt.Sprintf("has trailer %s", want))
+	}
+	if len(rule.paths) > 0 {
+		patterns := strings.Join(rule.paths, ", ")
+		files, err := c.changedFiles(commit)
+		if err != nil {
+			result.Detail = err.Error()
+			return result
+		}
+		if len(files) == 0 {
+			result.Detail = "commit changes no files"
+			return result
+		}
+		if _, other := splitFiles(rule.paths, files); len(other) > 0 {
+			result.Detail = fmt.Sprintf("%s doesn't match %s", other[0], patterns)
+			return result
+		}
+		found = append(found, fmt.Sprintf("only changes files matching %s", patterns))
+	}
+
+	result.Matched = true
+	result.Detail = strings.Join(found, ", ")
+	return result
+}
+
+// quoteOrNone quotes a parsed field, or shows it as (none) when it's empty
+func quoteOrNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return "'" + value + "'"
+}
@@ -0,0 +1,224 @@
+package lib
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// ForgeType identifies the service hosting the repository (GitHub, GitLab, etc.)
+type ForgeType string
+
+const (
+	ForgeGitHub    ForgeType = "github"
+	ForgeGitLab    ForgeType = "gitlab"
+	ForgeBitbucket ForgeType = "bitbucket"
+	ForgeGitea     ForgeType = "gitea"
+	ForgeCustom    ForgeType = "custom"
+)
+
+// forgeTemplates holds the URL patterns for one forge.
+// Placeholders: {base}, {hash}, {from}, {to}, {tag}, {number}
+type forgeTemplates struct {
+	Commit      string
+	Compare     string
+	Tag         string
+	PullRequest string
+}
+
+// Built-in URL patterns for the forges we know about
+var builtinForgeTemplates = map[ForgeType]forgeTemplates{
+	ForgeGitHub: {
+		Commit:      "{base}/commit/{hash}",
+		Compare:     "{base}/compare/{from}...{to}",
+		Tag:         "{base}/releases/tag/{tag}",
+		PullRequest: "{base}/pull/{number}",
+	},
+	ForgeGitLab: {
+		Commit:      "{base}/-/commit/{hash}",
+		Compare:     "{base}/-/compare/{from}...{to}",
+		Tag:         "{base}/-/tags/{tag}",
+		PullRequest: "{base}/-/merge_requests/{number}",
+	},
+	ForgeBitbucket: {
+		Commit:      "{base}/commits/{hash}",
+		Compare:     "{base}/branches/compare/{to}%0D{from}",
+		Tag:         "{base}/src/{tag}",
+		PullRequest: "{base}/pull-requests/{number}",
+	},
+	ForgeGitea: {
+		Commit:      "{base}/commit/{hash}",
+		Compare:     "{base}/compare/{from}...{to}",
+		Tag:         "{base}/releases/tag/{tag}",
+		PullRequest: "{base}/pulls/{number}",
+	},
+}
+
+// Forge builds web links for commits, tags, comparisons and pull requests
+type Forge struct {
+	Type      ForgeType
+	BaseURL   string
+	templates forgeTemplates
+}
+
+// DetectForge reads the configured remote and works out where the repository is hosted
+func DetectForge(repo *git.Repository, config *Config) (*Forge, error) {
+	base := config.Forge.URL
+	host := ""
+
+	if base == "" {
+		remoteName := config.Git.Remote
+		if remoteName == "" {
+			remoteName = "origin"
+		}
+
+		remote, err := repo.Remote(remoteName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read remote %q: %w", remoteName, err)
+		}
+
+		urls := remote.Config().URLs
+		if len(urls) == 0 {
+			return nil, fmt.Errorf("remote %q has no URL", remoteName)
+		}
+
+		base, host, err = parseRemoteURL(urls[0])
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		base = strings.TrimSuffix(base, "/")
+		if u, err := url.Parse(base); err == nil {
+			host = u.Hostname()
+		}
+	}
+
+	// Work out the forge type: explicit setting, then host mapping, then guess from hostname
+	forgeType := ForgeType(strings.ToLower(config.Forge.Type))
+	if forgeType == "" {
+		if mapped, ok := config.Forge.Hosts[host]; ok {
+			forgeType = ForgeType(strings.ToLower(mapped))
+		} else {
+			forgeType = guessForgeType(host)
+		}
+	}
+
+	templates, known := builtinForgeTemplates[forgeType]
+	if !known && forgeType != ForgeCustom {
+		return nil, fmt.Errorf("unknown forge type %q", forgeType)
+	}
+
+	// Custom patterns from config override the built-in ones
+	if config.Forge.CommitURL != "" {
+		templates.Commit = config.Forge.CommitURL
+	}
+	if config.Forge.CompareURL != "" {
+		templates.Compare = config.Forge.CompareURL
+	}
+	if config.Forge.TagURL != "" {
+		templates.Tag = config.Forge.TagURL
+	}
+	if config.Forge.PullRequestURL != "" {
+		templates.PullRequest = config.Forge.PullRequestURL
+	}
+
+	return &Forge{
+		Type:      forgeType,
+		BaseURL:   base,
+		templates: templates,
+	}, nil
+}
+
+// parseRemoteURL turns a git remote URL into a browsable https base URL and its host
+func parseRemoteURL(remote string) (string, string, error) {
+	remote = strings.TrimSuffix(strings.TrimSpace(remote), "/")
+	remote = strings.TrimSuffix(remote, ".git")
+
+	// scp-like syntax: git@github.com:owner/repo
+	if !strings.Contains(remote, "://") {
+		at := strings.Index(remote, "@")
+		colon := strings.Index(remote, ":")
+		if colon == -1 || colon < at {
+			return "", "", fmt.Errorf("unsupported remote URL %q", remote)
+		}
+		host := remote[at+1 : colon]
+		path := strings.TrimPrefix(remote[colon+1:], "/")
+		return "https://" + host + "/" + path, host, nil
+	}
+
+	u, err := url.Parse(remote)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid remote URL %q: %w", remote, err)
+	}
+
+	path := strings.TrimPrefix(u.Path, "/")
+	switch u.Scheme {
+	case "http", "https":
+		// Keep the scheme and any port, drop credentials
+		return u.Scheme + "://" + u.Host + "/" + path, u.Hostname(), nil
+	case "ssh", "git", "git+ssh":
+		// SSH ports are not the web port, so only keep the hostname
+		return "https://" + u.Hostname() + "/" + path, u.Hostname(), nil
+	}
+
+	return "", "", fmt.Errorf("unsupported remote URL scheme %q", u.Scheme)
+}
+
+// guessForgeType guesses the forge from well-known hostnames
+func guessForgeType(host string) ForgeType {
+	host = strings.ToLower(host)
+	switch {
+	case strings.Contains(host, "github"):
+		return ForgeGitHub
+	case strings.Contains(host, "gitlab"):
+		return ForgeGitLab
+	case strings.Contains(host, "bitbucket"):
+		return ForgeBitbucket
+	case strings.Contains(host, "gitea"), host == "codeberg.org":
+		return ForgeGitea
+	}
+	return ForgeCustom
+}
+
+// expand fills in a URL template, returning "" when the template is empty
+func (f *Forge) expand(template string, values ...string) string {
+	if f == nil || template == "" {
+		return ""
+	}
+	replacements := append([]string{"{base}", f.BaseURL}, values...)
+	return strings.NewReplacer(replacements...).Replace(template)
+}
+
+// CommitURL returns the web link for a commit
+func (f *Forge) CommitURL(hash string) string {
+	if f == nil {
+		return ""
+	}
+	return f.expand(f.templates.Commit, "{hash}", hash)
+}
+
+// CompareURL returns the web link comparing two revisions (e.g. v1.2.0...v1.3.0)
+func (f *Forge) CompareURL(from, to string) string {
+	if f == nil {
+		return ""
+	}
+	return f.expand(f.templates.Compare, "{from}", from, "{to}", to)
+}
+
+// TagURL returns the web link for a tag or release page
+func (f *Forge) TagURL(tag string) string {
+	if f == nil {
+		return ""
+	}
+	return f.expand(f.templates.Tag, "{tag}", tag)
+}
+
+// PullRequestURL returns the web link for a pull (or merge) request
+func (f *Forge) PullRequestURL(number string) string {
+	if f == nil {
+		return ""
+	}
+	return f.expand(f.templates.PullRequest, "{number}", number)
+}
@@ -2,19 +2,27 @@ package lib
 
 import (
 	"fmt"
-
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
 // Represent a git commit
 type Commit struct {
-	Hash    string
-	Author  string
-	Date    time.Time
-	Message string
+	Hash     string
+	FullHash string
+	Author   string
+	Email    string
+	Date     time.Time
+	Message  string
+
+	OriginalMessage string // message before an AI rewrite; empty if it wasn't rewritten
 }
 
 // CommitCategory represents the type of change a commit introduces (feature, bugfix, etc.)
@@ -32,50 +40,9 @@ const (
 	CategoryOther       CommitCategory = " Other"
 )
 
-// CategorizeCommit categorizes a commit based on its message
+// CategorizeCommit categorizes a commit based on its message, using the built-in rules
 func CategorizeCommit(commit *Commit) CommitCategory {
-	msg := commit.Message
-
-	// Check for breaking change indicator
-	if containsAny(msg, []string{"BREAKING CHANGE", "BREAKING:", "!"}) {
-		return CategoryBreaking
-	}
-
-	// Check conventional commit prefixes
-	if hasPrefix(msg, "feat") {
-		return CategoryFeature
-	}
-	if hasPrefix(msg, "fix") {
-		return CategoryFix
-	}
-	if hasPrefix(msg, "docs") {
-		return CategoryDocs
-	}
-	if hasPrefix(msg, "perf") {
-		return CategoryPerformance
-	}
-	if hasPrefix(msg, "refactor") {
-		return CategoryRefactor
-	}
-	if hasPrefix(msg, "test") {
-		return CategoryTest
-	}
-	if hasPrefix(msg, "chore") {
-		return CategoryChore
-	}
-
-	// Check for keywords in message
-	if containsAny(msg, []string{"add", "implement", "create", "new"}) {
-		return CategoryFeature
-	}
-	if containsAny(msg, []string{"fix", "bug", "issue", "resolve"}) {
-		return CategoryFix
-	}
-	if containsAny(msg, []string{"update", "improve"}) {
-		return CategoryRefactor
-	}
-
-	return CategoryOther
+	return defaultClassifier.Categorize(commit)
 }
 
 // hasPrefix checks if message starts with a conventional commit prefix
@@ -95,73 +62,17 @@ func hasPrefix(msg, prefix string) bool {
 	return false
 }
 
-// containsAny checks if message contains any of the keywords
-func containsAny(msg string, keywords []string) bool {
-	msgLower := toLower(msg)
-	for _, keyword := range keywords {
-		if contains(msgLower, toLower(keyword)) {
-			return true
-		}
-	}
-	return false
-}
-
-// Simple string helpers
-func toLower(s string) string {
-	result := ""
-	for _, c := range s {
-		if c >= 'A' && c <= 'Z' {
-			result += string(c + 32)
-		} else {
-			result += string(c)
-		}
-	}
-	return result
-}
-
-func contains(s, substr string) bool {
-	if len(substr) > len(s) {
-		return false
-	}
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
-	}
-	return false
-}
-
 // GroupCommitsByCategory groups commits by their category
 func GroupCommitsByCategory(commits []*Commit) map[CommitCategory][]*Commit {
-	groups := make(map[CommitCategory][]*Commit)
-
-	for _, commit := range commits {
-		category := CategorizeCommit(commit)
-		groups[category] = append(groups[category], commit)
-	}
-
-	return groups
+	return defaultClassifier.Group(commits)
 }
 
 // PrintGroupedCommits displays commits grouped by category
 func PrintGroupedCommits(groups map[CommitCategory][]*Commit) {
-	// Define order of categories
-	categories := []CommitCategory{
-		CategoryBreaking,
-		CategoryFeature,
-		CategoryFix,
-		CategoryPerformance,
-		CategoryRefactor,
-		CategoryDocs,
-		CategoryTest,
-		CategoryChore,
-		CategoryOther,
-	}
-
 	fmt.Println("Categorized Commits:")
 	fmt.Println()
 
-	for _, category := range categories {
+	for _, category := range CategoryOrder {
 		commits, exists := groups[category]
 		if !exists || len(commits) == 0 {
 			continue // Skip empty categories
@@ -209,12 +120,7 @@ func GetRecentCommits(repo *git.Repository, count int) ([]*Commit, error) {
 		}
 
 		// Add commit to our list
-		commits = append(commits, &Commit{
-			Hash:    c.Hash.String()[:7], // Short hash (first 7 chars)
-			Author:  c.Author.Name,
-			Date:    c.Author.When,
-			Message: c.Message,
-		})
+		commits = append(commits, newCommit(c))
 
 		return nil
 	})
@@ -227,6 +133,106 @@ func GetRecentCommits(repo *git.Repository, count int) ([]*Commit, error) {
 	return commits, nil
 }
 
+// GetCommitsSince gets all commits reachable from HEAD but not from since.
+// A zero since hash returns the whole history.
+func GetCommitsSince(repo *git.Repository, since plumbing.Hash) ([]*Commit, error) {
+	ref, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	return GetCommitsBetween(repo, since, ref.Hash())
+}
+
+// GetCommitsBetween gets the commits reachable from to but not from from (git log from..to)
+func GetCommitsBetween(repo *git.Repository, from, to plumbing.Hash) ([]*Commit, error) {
+	// Everything reachable from the starting point is already released
+	seen := make(map[plumbing.Hash]bool)
+	if !from.IsZero() {
+		fromIter, err := repo.Log(&git.LogOptions{From: from})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get log: %w", err)
+		}
+		err = fromIter.ForEach(func(c *object.Commit) error {
+			seen[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error iterating commits: %w", err)
+		}
+	}
+
+	commitIter, err := repo.Log(&git.LogOptions{From: to})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log: %w", err)
+	}
+
+	var commits []*Commit
+	err = commitIter.ForEach(func(c *object.Commit) error {
+		if !seen[c.Hash] {
+			commits = append(commits, newCommit(c))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error iterating commits: %w", err)
+	}
+
+	return commits, nil
+}
+
+// ResolveRevision resolves a revision such as "HEAD~3", a tag or a hash to a commit hash
+func ResolveRevision(repo *git.Repository, rev string) (plumbing.Hash, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to resolve %q: %w", rev, err)
+	}
+
+	// Annotated tags resolve to the tag object, so peel them to the commit
+	if tagObject, err := repo.TagObject(*hash); err == nil {
+		commit, err := tagObject.Commit()
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("tag %q does not point to a commit: %w", rev, err)
+		}
+		return commit.Hash, nil
+	}
+	return *hash, nil
+}
+
+// GetCommit reads a single commit, given as any revision git understands
+func GetCommit(repo *git.Repository, rev string) (*Commit, error) {
+	hash, err := ResolveRevision(repo, rev)
+	if err != nil {
+		return nil, err
+	}
+	c, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", rev, err)
+	}
+	return newCommit(c), nil
+}
+
+// CommitDate returns when a commit was made, as its committer date. Unreleased
+// changes are dated by HEAD, so regenerating an unchanged tree gives the same output.
+func CommitDate(repo *git.Repository, hash plumbing.Hash) (time.Time, error) {
+	c, err := repo.CommitObject(hash)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+	return c.Committer.When, nil
+}
+
+// newCommit converts a go-git commit into our Commit type
+func newCommit(c *object.Commit) *Commit {
+	return &Commit{
+		Hash:     c.Hash.String()[:7], // Short hash (first 7 chars)
+		FullHash: c.Hash.String(),
+		Author:   c.Author.Name,
+		Email:    c.Author.Email,
+		Date:     c.Author.When,
+		Message:  c.Message,
+	}
+}
+
 func PrintCommits(commits []*Commit) {
 	fmt.Println("Recent Commits: ")
 	fmt.Println()
@@ -238,3 +244,138 @@ func PrintCommits(commits []*Commit) {
 		fmt.Println()
 	}
 }
+
+// DefaultSignature returns the user identity from the git configuration
+func DefaultSignature(repo *git.Repository) (*object.Signature, error) {
+	cfg, err := repo.ConfigScoped(gitconfig.GlobalScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git config: %w", err)
+	}
+
+	name, email := cfg.User.Name, cfg.User.Email
+	if name == "" || email == "" {
+		return nil, fmt.Errorf("user.name and user.email must be set in git config")
+	}
+
+	return &object.Signature{Name: name, Email: email, When: time.Now()}, nil
+}
+
+// TagExists checks whether a tag with the given name exists
+func TagExists(repo *git.Repository, name string) bool {
+	_, err := repo.Tag(name)
+	return err == nil
+}
+
+// CommitFiles stages the given files and commits them, returning the new commit hash.
+// It refuses to commit when other changes are staged, since they would go into the commit too.
+func CommitFiles(repo *git.Repository, paths []string, message string, signature *object.Signature) (plumbing.Hash, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to open worktree: %w", err)
+	}
+
+	staged, err := OtherStagedPaths(repo, paths)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if len(staged) > 0 {
+		return plumbing.ZeroHash, fmt.Errorf("other changes are staged (%s); commit or unstage them first", strings.Join(staged, ", "))
+	}
+
+	rels, err := worktreePaths(worktree, paths)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	for i, rel := range rels {
+		if _, err := worktree.Add(rel); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to stage %s: %w", paths[i], err)
+		}
+	}
+
+	hash, err := worktree.Commit(message, &git.CommitOptions{
+		Author:    signature,
+		Committer: signature,
+	})
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to commit: %w", err)
+	}
+	return hash, nil
+}
+
+// ResetToCommit moves the current branch and the index back to a commit,
+// leaving the files in the worktree as they are
+func ResetToCommit(repo *git.Repository, hash plumbing.Hash) error {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to open worktree: %w", err)
+	}
+	if err := worktree.Reset(&git.ResetOptions{Commit: hash, Mode: git.MixedReset}); err != nil {
+		return fmt.Errorf("failed to reset to %s: %w", hash.String()[:7], err)
+	}
+	return nil
+}
+
+// OtherStagedPaths lists the staged files that are not among the given paths
+func OtherStagedPaths(repo *git.Repository, paths []string) ([]string, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open worktree: %w", err)
+	}
+	rels, err := worktreePaths(worktree, paths)
+	if err != nil {
+		return nil, err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read worktree status: %w", err)
+	}
+
+	wanted := make(map[string]bool)
+	for _, rel := range rels {
+		wanted[rel] = true
+	}
+	var staged []string
+	for path, file := range status {
+		if file.Staging != git.Unmodified && file.Staging != git.Untracked && !wanted[path] {
+			staged = append(staged, path)
+		}
+	}
+	sort.Strings(staged)
+	return staged, nil
+}
+
+// worktreePaths converts paths to the slash-separated form the worktree uses,
+// relative to the repository root
+func worktreePaths(worktree *git.Worktree, paths []string) ([]string, error) {
+	root, err := filepath.Abs(worktree.Filesystem.Root())
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve worktree: %w", err)
+	}
+
+	var rels []string
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+		}
+		// Rel only fails across volumes; a path outside the root comes back as "../..."
+		rel, err := filepath.Rel(root, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("%s is outside the repository", path)
+		}
+		rels = append(rels, filepath.ToSlash(rel))
+	}
+	return rels, nil
+}
+
+// CreateAnnotatedTag creates an annotated tag pointing at the given commit
+func CreateAnnotatedTag(repo *git.Repository, name string, hash plumbing.Hash, message string, signature *object.Signature) error {
+	_, err := repo.CreateTag(name, hash, &git.CreateTagOptions{
+		Tagger:  signature,
+		Message: message,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create tag %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package lib
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// gitmojis is the built-in gitmoji table. The first entry of a category is
+// the emoji used in its headings.
+var gitmojis = []struct {
+	code     string
+	emoji    string
+	category CommitCategory
+}{
+	{":boom:", "💥", CategoryBreaking},
+	{":sparkles:", "✨", CategoryFeature},
+	{":bug:", "🐛", CategoryFix},
+	{":ambulance:", "🚑", CategoryFix},
+	{":adhesive_bandage:", "🩹", CategoryFix},
+	{":lock:", "🔒", CategoryFix},
+	{":pencil2:", "✏️", CategoryFix},
+	{":zap:", "⚡️", CategoryPerformance},
+	{":recycle:", "♻️", CategoryRefactor},
+	{":art:", "🎨", CategoryRefactor},
+	{":fire:", "🔥", CategoryRefactor},
+	{":truck:", "🚚", CategoryRefactor},
+	{":memo:", "📝", CategoryDocs},
+	{":bulb:", "💡", CategoryDocs},
+	{":white_check_mark:", "✅", CategoryTest},
+	{":test_tube:", "🧪", CategoryTest},
+	{":wrench:", "🔧", CategoryChore},
+	{":arrow_up:", "⬆️", CategoryChore},
+	{":arrow_down:", "⬇️", CategoryChore},
+	{":heavy_plus_sign:", "➕", CategoryChore},
+	{":heavy_minus_sign:", "➖", CategoryChore},
+	{":construction_worker:", "👷", CategoryChore},
+	{":green_heart:", "💚", CategoryChore},
+	{":rotating_light:", "🚨", CategoryChore},
+	{":bookmark:", "🔖", CategoryChore},
+	{":rocket:", "🚀", CategoryChore},
+}
+
+// builtinGitmojiTable is the built-in table, for parsing without a classifier
+var builtinGitmojiTable = builtinGitmoji()
+
+// builtinGitmoji maps each built-in shortcode and emoji to its category
+func builtinGitmoji() map[string]CommitCategory {
+	table := make(map[string]CommitCategory)
+	for _, g := range gitmojis {
+		table[gitmojiKey(g.code)] = g.category
+		table[gitmojiKey(g.emoji)] = g.category
+	}
+	return table
+}
+
+// CategoryEmoji returns the gitmoji for a category's headings, or "" if it has none
+func CategoryEmoji(category CommitCategory) string {
+	for _, g := range gitmojis {
+		if g.category == category {
+			return g.emoji
+		}
+	}
+	return ""
+}
+
+// gitmojiKey normalizes a shortcode or emoji for lookups: shortcodes ignore
+// case and emoji ignore variation selectors
+func gitmojiKey(gitmoji string) string {
+	if strings.HasPrefix(gitmoji, ":") {
+		return strings.ToLower(gitmoji)
+	}
+	return strings.ReplaceAll(gitmoji, "\ufe0f", "")
+}
+
+// gitmojiShortcode matches a shortcode like ":sparkles:"
+var gitmojiShortcode = regexp.MustCompile(`^:[a-zA-Z0-9_+\-]+:`)
+
+// SplitGitmoji splits a leading gitmoji, and the spaces after it, from text.
+// Text that doesn't start with one is returned unchanged with an empty gitmoji.
+// Only shortcodes in the built-in table count as gitmoji.
+func SplitGitmoji(text string) (gitmoji, rest string) {
+	return splitGitmoji(text, builtinGitmojiTable)
+}
+
+// splitGitmoji splits a leading emoji, or a shortcode that is in table, from text.
+// Other shortcodes stay, so a subject like ":wip: parser" keeps its first word.
+func splitGitmoji(text string, table map[string]CommitCategory) (gitmoji, rest string) {
+	if code := gitmojiShortcode.FindString(text); code != "" {
+		if _, ok := table[gitmojiKey(code)]; !ok {
+			return "", text
+		}
+		return code, strings.TrimLeft(text[len(code):], " \t")
+	}
+	return splitEmoji(text)
+}
+
+// validGitmoji reports whether text is a single shortcode or emoji
+func validGitmoji(text string) bool {
+	if code := gitmojiShortcode.FindString(text); code != "" {
+		return code == text
+	}
+	emoji, rest := splitEmoji(text)
+	return emoji != "" && rest == ""
+}
+
+// splitEmoji splits a leading emoji sequence, and the spaces after it, from text
+func splitEmoji(text string) (emoji, rest string) {
+	end := 0
+	for end < len(text) {
+		r, size := utf8.DecodeRuneInString(text[end:])
+		// The first rune must be an emoji; joiners, variation selectors and
+		// skin tones may follow it, and a joiner may be followed by another emoji
+		joined := end > 0 && (r == '\u200d' || r == '\ufe0f' || (r >= 0x1F3FB && r <= 0x1F3FF) ||
+			(isEmoji(r) && strings.HasSuffix(text[:end], "\u200d")))
+		if !(end == 0 && isEmoji(r)) && !joined {
+			break
+		}
+		end += size
+	}
+	if end == 0 {
+		return "", text
+	}
+	return text[:end], strings.TrimLeft(text[end:], " \t")
+}
+
+// isEmoji reports whether r is in one of the pictographic blocks gitmoji use
+func isEmoji(r rune) bool {
+	return (r >= 0x1F000 && r <= 0x1FAFF) || // pictographs, emoticons, transport, supplemental symbols
+		(r >= 0x2600 && r <= 0x27BF) || // miscellaneous symbols, dingbats
+		(r >= 0x2300 && r <= 0x23FF) || // technical symbols such as ⏪
+		(r >= 0x2B00 && r <= 0x2BFF) // arrows and stars such as ⬆ and ⭐
+}
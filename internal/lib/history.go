@@ -0,0 +1,106 @@
+package lib
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// ReleaseRange is a version together with the commits that went into it
+type ReleaseRange struct {
+	Version     string // empty for unreleased commits
+	Tag         string
+	PreviousTag string
+	Date        time.Time
+	Commits     []*Commit
+}
+
+// Build groups the range's commits into a release
+func (r *ReleaseRange) Build(classifier *Classifier) *Release {
+	release := BuildRelease(r.Commits, r.Version, r.Date, classifier)
+	release.Tag = r.Tag
+	release.PreviousTag = r.PreviousTag
+	return release
+}
+
+// ListReleaseRanges returns a range for every version tag, newest first, with an
+// unreleased range on top for commits made since the latest tag.
+// Pre-releases that already have a final release are rolled up into it.
+func ListReleaseRanges(repo *git.Repository, config *Config) ([]*ReleaseRange, error) {
+	scheme, err := NewVersionScheme(config)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := FindVersionTags(repo, config.Versioning.TagPrefix, scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	var ranges []*ReleaseRange
+
+	// Commits since the latest tag are unreleased
+	latest := LatestVersionTag(tags, true)
+	since := plumbing.ZeroHash
+	if latest != nil {
+		since = latest.Hash
+	}
+	unreleased, err := GetCommitsBetween(repo, since, head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if len(unreleased) > 0 {
+		date, err := CommitDate(repo, head.Hash())
+		if err != nil {
+			return nil, err
+		}
+		r := &ReleaseRange{Date: date, Commits: unreleased}
+		if latest != nil {
+			r.PreviousTag = latest.Name
+		}
+		ranges = append(ranges, r)
+	}
+
+	for _, tag := range tags {
+		if tag.Version.IsPreRelease() && hasFinalRelease(tags, tag.Version) {
+			continue
+		}
+
+		previous := previousVersionTag(tags, tag, scheme)
+		from := plumbing.ZeroHash
+		if previous != nil {
+			from = previous.Hash
+		}
+
+		commits, err := GetCommitsBetween(repo, from, tag.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		r := &ReleaseRange{Version: tag.Version.String(), Tag: tag.Name, Date: tag.Date, Commits: commits}
+		if previous != nil {
+			r.PreviousTag = previous.Name
+		}
+		ranges = append(ranges, r)
+	}
+
+	return ranges, nil
+}
+
+// hasFinalRelease checks whether a pre-release has been followed by its final release
+func hasFinalRelease(tags []*VersionTag, pre *Version) bool {
+	for _, tag := range tags {
+		v := tag.Version
+		if !v.IsPreRelease() && v.Major == pre.Major && v.Minor == pre.Minor && v.Patch == pre.Patch {
+			return true
+		}
+	}
+	return false
+}
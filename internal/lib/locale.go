@@ -0,0 +1,293 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Catalog holds the translated strings for one locale.
+// A nil catalog is English.
+type Catalog struct {
+	Locale     string            `yaml:"-"`
+	Messages   map[string]string `yaml:"messages"`    // built-in labels such as "version"
+	Categories map[string]string `yaml:"categories"`  // category titles by key, e.g. "features"
+	Months     []string          `yaml:"months"`      // January to December
+	DateFormat string            `yaml:"date_format"` // e.g. "{month} {day}, {year}"
+}
+
+// builtinCatalogs are the locales that ship with the tool
+var builtinCatalogs = map[string]*Catalog{
+	"en": {
+		Messages: map[string]string{
+			"changelog":          "Changelog",
+			"version":            "Version",
+			"unreleased":         "Unreleased",
+			"generated":          "Generated",
+			"compare":            "Compare",
+			"contributors":       "Contributors",
+			"first_contribution": "first contribution",
+			"total_commits":      "Total commits",
+			"details":            "Details",
+		},
+		Categories: map[string]string{
+			"breaking":      "Breaking Changes",
+			"features":      "Features",
+			"fixes":         "Bug Fixes",
+			"performance":   "Performance",
+			"refactoring":   "Refactoring",
+			"documentation": "Documentation",
+			"tests":         "Tests",
+			"chores":        "Chores",
+			"other":         "Other",
+		},
+		Months: []string{"January", "February", "March", "April", "May", "June",
+			"July", "August", "September", "October", "November", "December"},
+		DateFormat: "{month} {day}, {year}",
+	},
+	"de": {
+		Messages: map[string]string{
+			"changelog":          "Änderungsprotokoll",
+			"version":            "Version",
+			"unreleased":         "Unveröffentlicht",
+			"generated":          "Erstellt",
+			"compare":            "Vergleich",
+			"contributors":       "Mitwirkende",
+			"first_contribution": "erster Beitrag",
+			"total_commits":      "Commits insgesamt",
+			"details":            "Details",
+		},
+		Categories: map[string]string{
+			"breaking":      "Inkompatible Änderungen",
+			"features":      "Neue Funktionen",
+			"fixes":         "Fehlerbehebungen",
+			"performance":   "Leistung",
+			"refactoring":   "Refactoring",
+			"documentation": "Dokumentation",
+			"tests":         "Tests",
+			"chores":        "Wartung",
+			"other":         "Sonstiges",
+		},
+		Months: []string{"Januar", "Februar", "März", "April", "Mai", "Juni",
+			"Juli", "August", "September", "Oktober", "November", "Dezember"},
+		DateFormat: "{day}. {month} {year}",
+	},
+	"fr": {
+		Messages: map[string]string{
+			"changelog":          "Journal des modifications",
+			"version":            "Version",
+			"unreleased":         "Non publié",
+			"generated":          "Généré le",
+			"compare":            "Comparer",
+			"contributors":       "Contributeurs",
+			"first_contribution": "première contribution",
+			"total_commits":      "Nombre total de commits",
+			"details":            "Détails",
+		},
+		Categories: map[string]string{
+			"breaking":      "Changements incompatibles",
+			"features":      "Nouvelles fonctionnalités",
+			"fixes":         "Corrections de bugs",
+			"performance":   "Performances",
+			"refactoring":   "Refactorisation",
+			"documentation": "Documentation",
+			"tests":         "Tests",
+			"chores":        "Maintenance",
+			"other":         "Autres",
+		},
+		Months: []string{"janvier", "février", "mars", "avril", "mai", "juin",
+			"juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		DateFormat: "{day} {month} {year}",
+	},
+	"es": {
+		Messages: map[string]string{
+			"changelog":          "Registro de cambios",
+			"version":            "Versión",
+			"unreleased":         "Sin publicar",
+			"generated":          "Generado",
+			"compare":            "Comparar",
+			"contributors":       "Colaboradores",
+			"first_contribution": "primera contribución",
+			"total_commits":      "Total de commits",
+			"details":            "Detalles",
+		},
+		Categories: map[string]string{
+			"breaking":      "Cambios incompatibles",
+			"features":      "Novedades",
+			"fixes":         "Correcciones",
+			"performance":   "Rendimiento",
+			"refactoring":   "Refactorización",
+			"documentation": "Documentación",
+			"tests":         "Pruebas",
+			"chores":        "Mantenimiento",
+			"other":         "Otros",
+		},
+		Months: []string{"enero", "febrero", "marzo", "abril", "mayo", "junio",
+			"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		DateFormat: "{day} de {month} de {year}",
+	},
+}
+
+// LoadCatalog builds the catalog for a locale such as "de" or "pt-BR".
+// Strings missing from a locale fall back to its base language and then to English.
+// A file named <locale>.yaml in dir, if there is one, overrides the built-in strings.
+// Without a locale it returns nil, which keeps the default output.
+func LoadCatalog(locale, dir string) (*Catalog, error) {
+	if locale == "" {
+		return nil, nil
+	}
+	catalog := mergeCatalog(&Catalog{}, builtinCatalogs["en"])
+
+	base := strings.SplitN(strings.ReplaceAll(locale, "_", "-"), "-", 2)[0]
+	found := false
+	for _, name := range []string{base, locale} {
+		if builtin, ok := builtinCatalogs[name]; ok {
+			catalog = mergeCatalog(catalog, builtin)
+			found = true
+		}
+
+		if dir == "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name+".yaml"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read catalog: %w", err)
+		}
+		var custom Catalog
+		if err := yaml.Unmarshal(data, &custom); err != nil {
+			return nil, fmt.Errorf("failed to parse catalog %s.yaml: %w", name, err)
+		}
+		catalog = mergeCatalog(catalog, &custom)
+		found = true
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no catalog for locale %q", locale)
+	}
+	catalog.Locale = locale
+	if len(catalog.Months) != 12 {
+		return nil, fmt.Errorf("catalog for locale %q must list 12 months", locale)
+	}
+	return catalog, nil
+}
+
+// mergeCatalog returns a copy of base with the strings set in overlay replaced
+func mergeCatalog(base, overlay *Catalog) *Catalog {
+	merged := &Catalog{
+		Messages:   make(map[string]string),
+		Categories: make(map[string]string),
+		Months:     base.Months,
+		DateFormat: base.DateFormat,
+	}
+	for _, c := range []*Catalog{base, overlay} {
+		for key, value := range c.Messages {
+			merged.Messages[key] = value
+		}
+		for key, value := range c.Categories {
+			merged.Categories[key] = value
+		}
+	}
+	if len(overlay.Months) > 0 {
+		merged.Months = overlay.Months
+	}
+	if overlay.DateFormat != "" {
+		merged.DateFormat = overlay.DateFormat
+	}
+	return merged
+}
+
+// Language returns the locale as a language tag, e.g. "pt-BR"
+func (c *Catalog) Language() string {
+	if c == nil || c.Locale == "" {
+		return "en"
+	}
+	return strings.ReplaceAll(c.Locale, "_", "-")
+}
+
+// T returns the translation of a built-in label
+func (c *Catalog) T(key string) string {
+	if c != nil {
+		if message, ok := c.Messages[key]; ok {
+			return message
+		}
+	}
+	return builtinCatalogs["en"].Messages[key]
+}
+
+// Category returns the title of a category
+func (c *Catalog) Category(category CommitCategory) string {
+	if c != nil {
+		if title, ok := c.Categories[category.Key()]; ok {
+			return title
+		}
+	}
+	if title, ok := builtinCatalogs["en"].Categories[category.Key()]; ok {
+		return title
+	}
+	return strings.TrimSpace(string(category))
+}
+
+// ParseDate reads a date written by FormatDate, ignoring the case of the month
+func (c *Catalog) ParseDate(text string) (time.Time, bool) {
+	if c == nil {
+		c = builtinCatalogs["en"]
+	}
+	months := make([]string, len(c.Months))
+	for i, month := range c.Months {
+		months[i] = regexp.QuoteMeta(month)
+	}
+	pattern := strings.NewReplacer(
+		`\{day\}`, `(?P<day>\d{1,2})`,
+		`\{month\}`, `(?P<month>`+strings.Join(months, "|")+`)`,
+		`\{year\}`, `(?P<year>\d{4})`,
+	).Replace(regexp.QuoteMeta(c.DateFormat))
+	format, err := regexp.Compile(`(?i)^` + pattern + `$`)
+	if err != nil {
+		return time.Time{}, false
+	}
+	match := format.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return time.Time{}, false
+	}
+
+	var day, month, year int
+	for i, group := range format.SubexpNames() {
+		switch group {
+		case "day":
+			day, _ = strconv.Atoi(match[i])
+		case "year":
+			year, _ = strconv.Atoi(match[i])
+		case "month":
+			for m, name := range c.Months {
+				if strings.EqualFold(match[i], name) {
+					month = m + 1
+				}
+			}
+		}
+	}
+	if day == 0 || month == 0 || year == 0 {
+		return time.Time{}, false
+	}
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC), true
+}
+
+// FormatDate formats a date the way the locale writes it, e.g. "2. Januar 2006"
+func (c *Catalog) FormatDate(t time.Time) string {
+	if c == nil {
+		c = builtinCatalogs["en"]
+	}
+	return strings.NewReplacer(
+		"{day}", strconv.Itoa(t.Day()),
+		"{month}", c.Months[t.Month()-1],
+		"{year}", strconv.Itoa(t.Year()),
+	).Replace(c.DateFormat)
+}
@@ -0,0 +1,398 @@
+package lib
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Patterns for the parts of a Markdown changelog
+var (
+	// "## Version 1.2.0", "## [1.2.0] - 2026-10-01", "## v1.2.0 (2026-10-01)"; the word
+	// before the version may be translated
+	parsedReleaseHeading = regexp.MustCompile(`^(?:[^\s\d\[]+\s+)?\[?v?(\d[^\s\]]*)\]?(?:\s*[-–(]?\s*(\d{4}-\d{2}-\d{2})\)?)?`)
+	// "**Generated:** January 2, 2006" or "**Compare:** [...](url)", with a translated label
+	metadataLine = regexp.MustCompile(`^\*\*([^*]+):\*\*\s*(.+)$`)
+	// "[v1.0.0...v1.1.0](url)" after the compare label
+	compareTarget = regexp.MustCompile(`^\[([^\]]+)\.\.\.([^\]]+)\]`)
+	// A trailing "([abc1234](url))" or "([abc1234])" commit reference
+	entryCommit = regexp.MustCompile(`\s*\(\[([0-9a-f]{7,40})\](?:\(([^)]*)\))?\)\s*$`)
+	// "[#12](url)" pull request links, turned back into "#12"
+	pullRequestLink = regexp.MustCompile(`\[(#\d+)\]\([^)]*\)`)
+	// "[1.2.0]: https://..." reference link definitions
+	referenceLink = regexp.MustCompile(`^\[([^\]]+)\]:\s*(\S+)`)
+	// ".../compare/v1.0.0...v1.1.0" in a reference URL
+	compareRange = regexp.MustCompile(`([^/]+)\.\.\.([^/]+)$`)
+	// ".../releases/tag/v1.0.0" or ".../tags/v1.0.0" in a reference URL
+	tagPath = regexp.MustCompile(`/tags?/([^/]+)$`)
+	// A full commit hash at the end of a URL
+	fullHashURL = regexp.MustCompile(`/([0-9a-f]{40})$`)
+)
+
+// ParseMarkdownChangelog reads a Markdown changelog into the release model.
+// It understands the format written by this tool, Keep a Changelog, and most
+// hand-written files that use "## version" headings with bulleted entries.
+// Labels and dates are read in every built-in locale and in catalog, the
+// configured one, which may be nil.
+func ParseMarkdownChangelog(content string, catalog *Catalog) *Changelog {
+	labels := newParseLabels(catalog)
+	changelog := &Changelog{}
+	references := make(map[string]string)
+
+	var release *Release
+	var section *Section
+	var entry *Entry
+	inContributors := false
+
+	// Entries listed without a category heading, sorted into sections at the end
+	uncategorized := make(map[*Release][]*Entry)
+
+	blank := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		trimmed := strings.TrimSpace(line)
+
+		// Indented lines below an entry continue it, even after a blank line
+		if entry != nil && (trimmed == "" || line != trimmed) {
+			if trimmed == "" {
+				blank = true
+			} else {
+				addEntryContinuation(entry, line, blank)
+				blank = false
+			}
+			continue
+		}
+		blank = false
+
+		switch {
+		case strings.HasPrefix(line, "# "):
+			changelog.Title = labels.title(line)
+			entry = nil
+
+		case strings.HasPrefix(line, "## "):
+			release = labels.releaseHeading(strings.TrimSpace(line[3:]))
+			changelog.Releases = append(changelog.Releases, release)
+			section, entry, inContributors = nil, nil, false
+
+		case release == nil:
+			// Anything before the first release is preamble
+			if match := referenceLink.FindStringSubmatch(trimmed); match != nil {
+				references[match[1]] = match[2]
+			}
+
+		case strings.HasPrefix(line, "### "):
+			title := strings.TrimSpace(line[4:])
+			section, entry = nil, nil
+			inContributors = labels.is("contributors", title)
+			if !inContributors {
+				section = &Section{Category: labels.category(title), Title: title}
+				release.Sections = append(release.Sections, section)
+			}
+
+		case trimmed == "---":
+			// The footer closes the last release
+			release, section, entry = nil, nil, nil
+
+		case referenceLink.MatchString(trimmed):
+			match := referenceLink.FindStringSubmatch(trimmed)
+			references[match[1]] = match[2]
+			entry = nil
+
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* "):
+			text := strings.TrimSpace(trimmed[2:])
+			if inContributors {
+				release.Contributors = append(release.Contributors, labels.contributor(text))
+				continue
+			}
+			entry = parseEntryLine(text)
+			if section == nil {
+				uncategorized[release] = append(uncategorized[release], entry)
+			} else {
+				section.Entries = append(section.Entries, entry)
+			}
+
+		case trimmed == "":
+			entry = nil
+
+		default:
+			entry = nil
+			labels.releaseMetadata(release, trimmed)
+		}
+	}
+
+	for _, r := range changelog.Releases {
+		if entries := uncategorized[r]; len(entries) > 0 {
+			r.Sections = append(categorizeEntries(entries), r.Sections...)
+		}
+
+		// Keep a Changelog puts compare links in reference definitions
+		url, ok := references[r.Version]
+		if r.IsUnreleased() {
+			url, ok = labels.reference(references)
+		}
+		if !ok || r.PreviousTag != "" || r.Tag != "" {
+			continue
+		}
+		if match := compareRange.FindStringSubmatch(url); match != nil {
+			r.PreviousTag = match[1]
+			if match[2] != "HEAD" {
+				r.Tag = match[2]
+			}
+		} else if match := tagPath.FindStringSubmatch(url); match != nil {
+			r.Tag = match[1]
+		}
+	}
+
+	return changelog
+}
+
+// parseLabels are the catalogs whose labels a changelog may be written with
+type parseLabels struct {
+	catalogs []*Catalog
+}
+
+// newParseLabels reads labels in English, the other built-in locales and the
+// configured catalog, if there is one
+func newParseLabels(catalog *Catalog) *parseLabels {
+	labels := &parseLabels{catalogs: []*Catalog{builtinCatalogs["en"]}}
+	var locales []string
+	for locale := range builtinCatalogs {
+		if locale != "en" {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales)
+	for _, locale := range locales {
+		labels.catalogs = append(labels.catalogs, builtinCatalogs[locale])
+	}
+	if catalog != nil {
+		labels.catalogs = append(labels.catalogs, catalog)
+	}
+	return labels
+}
+
+// is reports whether text is the built-in label key in any of the locales
+func (l *parseLabels) is(key, text string) bool {
+	for _, catalog := range l.catalogs {
+		if strings.EqualFold(text, catalog.T(key)) {
+			return true
+		}
+	}
+	return false
+}
+
+// title extracts the project name from "# Changelog - Name"
+func (l *parseLabels) title(line string) string {
+	title := strings.TrimSpace(strings.TrimPrefix(line, "# "))
+	for _, catalog := range l.catalogs {
+		if name, ok := strings.CutPrefix(title, catalog.T("changelog")+" - "); ok {
+			return unescapeMarkdown(name)
+		}
+	}
+	if l.is("changelog", title) {
+		return ""
+	}
+	return unescapeMarkdown(title)
+}
+
+// releaseHeading reads the version and date from a release heading
+func (l *parseLabels) releaseHeading(heading string) *Release {
+	release := &Release{}
+	if l.is("unreleased", strings.Trim(heading, "[]")) {
+		return release
+	}
+
+	match := parsedReleaseHeading.FindStringSubmatch(heading)
+	if match == nil {
+		// Not a version we understand; keep the heading as written
+		release.Version = heading
+		return release
+	}
+
+	release.Version = unescapeMarkdown(match[1])
+	if match[2] != "" {
+		if date, err := time.Parse("2006-01-02", match[2]); err == nil {
+			release.Date = date
+		}
+	}
+	return release
+}
+
+// releaseMetadata picks up the date and compare range lines of this tool's format
+func (l *parseLabels) releaseMetadata(release *Release, line string) {
+	match := metadataLine.FindStringSubmatch(line)
+	if match == nil {
+		return
+	}
+	label, value := strings.TrimSpace(match[1]), strings.TrimSpace(match[2])
+
+	switch {
+	case l.is("generated", label):
+		for _, catalog := range l.catalogs {
+			if date, ok := catalog.ParseDate(value); ok {
+				release.Date = date
+				return
+			}
+		}
+	case l.is("compare", label):
+		if target := compareTarget.FindStringSubmatch(value); target != nil {
+			release.PreviousTag = unescapeMarkdown(target[1])
+			if target[2] != "HEAD" {
+				release.Tag = unescapeMarkdown(target[2])
+			}
+		}
+	}
+}
+
+// reference finds the reference definition of the unreleased section
+func (l *parseLabels) reference(references map[string]string) (string, bool) {
+	for _, catalog := range l.catalogs {
+		if url, ok := references[catalog.T("unreleased")]; ok {
+			return url, true
+		}
+	}
+	return "", false
+}
+
+// parseEntryLine splits an entry into its text and commit reference
+func parseEntryLine(text string) *Entry {
+	entry := &Entry{}
+	if match := entryCommit.FindStringSubmatch(text); match != nil {
+		entry.Hash = match[1]
+		if url := fullHashURL.FindStringSubmatch(match[2]); url != nil {
+			entry.FullHash = url[1]
+		}
+		text = text[:len(text)-len(match[0])]
+	}
+	entry.Text = unescapeMarkdown(pullRequestLink.ReplaceAllString(text, "$1"))
+
+	header := ParseCommitHeader(entry.Text)
+	entry.Type = header.Type
+	entry.Scope = header.Scope
+	return entry
+}
+
+// detailsMarkup matches the lines of a collapsible <details> block around an entry body
+var detailsMarkup = regexp.MustCompile(`^(</?details>|<summary>.*</summary>)$`)
+
+// escapedBodyLine matches a body line whose leading Markdown was escaped when it was written
+var escapedBodyLine = regexp.MustCompile(`^(\s*)\\([#*_=-])`)
+
+// escapedTag matches an HTML tag in a body that was escaped when it was written
+var escapedTag = regexp.MustCompile(`\\<([A-Za-z/!?])`)
+
+// addEntryContinuation adds an indented line below an entry: either the rest of a
+// wrapped entry, or a line of the entry's body
+func addEntryContinuation(entry *Entry, line string, blank bool) {
+	trimmed := strings.TrimSpace(line)
+	if detailsMarkup.MatchString(trimmed) {
+		return
+	}
+
+	// Hand-written entries are often wrapped over several lines
+	if entry.Hash == "" && entry.Body == "" && !blank {
+		entry.Text += " " + trimmed
+		return
+	}
+
+	line = escapedBodyLine.ReplaceAllString(strings.TrimPrefix(line, "  "), "$1$2")
+	line = escapedTag.ReplaceAllString(line, "<$1")
+	if entry.Body != "" {
+		if blank {
+			entry.Body += "\n"
+		}
+		entry.Body += "\n"
+	}
+	entry.Body += line
+}
+
+// unescapeMarkdown removes the backslash escapes from a line of Markdown text,
+// leaving code spans as they are
+func unescapeMarkdown(text string) string {
+	var out strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '\\' && i+1 < len(text) && isASCIIPunct(text[i+1]):
+			i++
+			out.WriteByte(text[i])
+		case c == '`':
+			end := strings.IndexByte(text[i+1:], '`')
+			if end < 0 {
+				out.WriteByte(c)
+				continue
+			}
+			out.WriteString(text[i : i+end+2])
+			i += end + 1
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String()
+}
+
+// isASCIIPunct reports whether c is a character Markdown lets you escape
+func isASCIIPunct(c byte) bool {
+	return c >= '!' && c <= '/' || c >= ':' && c <= '@' || c >= '[' && c <= '`' || c >= '{' && c <= '~'
+}
+
+// contributor reads "Name *(first contribution)*"
+func (l *parseLabels) contributor(text string) *Contributor {
+	for _, catalog := range l.catalogs {
+		marker := "*(" + catalog.T("first_contribution") + ")*"
+		if name, ok := strings.CutSuffix(text, marker); ok {
+			return &Contributor{Name: unescapeMarkdown(strings.TrimSpace(name)), FirstTime: true}
+		}
+	}
+	return &Contributor{Name: unescapeMarkdown(text)}
+}
+
+// categorizeEntries sorts entries into sections by guessing their category from the text
+func categorizeEntries(entries []*Entry) []*Section {
+	grouped := make(map[CommitCategory][]*Entry)
+	for _, entry := range entries {
+		category := CategorizeCommit(&Commit{Message: entry.Text})
+		grouped[category] = append(grouped[category], entry)
+	}
+
+	var sections []*Section
+	for _, category := range CategoryOrder {
+		if len(grouped[category]) > 0 {
+			sections = append(sections, &Section{Category: category, Entries: grouped[category]})
+		}
+	}
+	return sections
+}
+
+// keepAChangelogCategories maps Keep a Changelog section titles to categories
+var keepAChangelogCategories = map[string]CommitCategory{
+	"added":      CategoryFeature,
+	"fixed":      CategoryFix,
+	"security":   CategoryFix,
+	"changed":    CategoryOther,
+	"deprecated": CategoryOther,
+	"removed":    CategoryOther,
+}
+
+// category finds the category for a section heading
+func (l *parseLabels) category(title string) CommitCategory {
+	// Headings may start with the category's gitmoji, "✨ Features"
+	_, title = SplitGitmoji(strings.TrimSpace(title))
+	for _, catalog := range l.catalogs {
+		for key, name := range catalog.Categories {
+			if category, ok := CategoryForKey(key); ok && strings.EqualFold(title, name) {
+				return category
+			}
+		}
+	}
+	name := strings.ToLower(title)
+	for _, category := range CategoryOrder {
+		if strings.ToLower(strings.TrimSpace(string(category))) == name {
+			return category
+		}
+	}
+	if category, ok := keepAChangelogCategories[name]; ok {
+		return category
+	}
+	return CategoryOther
+}
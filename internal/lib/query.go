@@ -0,0 +1,224 @@
+package lib
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// CommitFilter selects commits by their parsed fields. Every field that is set
+// must match; a list matches when any of its values does.
+type CommitFilter struct {
+	Types      []string        // conventional commit types, e.g. "feat"
+	Scopes     []string        // conventional commit scopes
+	Categories []string        // category keys, e.g. "fixes"
+	Breaking   bool            // only breaking changes
+	Author     *regexp.Regexp  // matched against "Name <email>"
+	Paths      []string        // files or directories the commit touches; globs are allowed
+	After      time.Time       // commits made at or after this time
+	Before     time.Time       // commits made before this time
+	Trailers   []TrailerFilter // trailers the commit must have
+	Grep       *regexp.Regexp  // matched against the whole message
+
+	IncludeExcluded bool // also match commits the exclusion rules leave out
+}
+
+// TrailerFilter matches commits with a trailer, optionally containing a value
+type TrailerFilter struct {
+	Key   string
+	Value string // empty matches any value
+}
+
+// ParseTrailerFilter reads "Key" or "Key=value"
+func ParseTrailerFilter(text string) (TrailerFilter, error) {
+	key, value, _ := strings.Cut(text, "=")
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return TrailerFilter{}, fmt.Errorf("invalid trailer filter %q, expected Key or Key=value", text)
+	}
+	return TrailerFilter{Key: key, Value: strings.TrimSpace(value)}, nil
+}
+
+// FilterCommits returns the commits that match the filter, in their original order.
+// Types, scopes and categories are read with the classifier, which may be nil.
+func FilterCommits(repo *git.Repository, commits []*Commit, filter CommitFilter, classifier *Classifier) ([]*Commit, error) {
+	var matched []*Commit
+	for _, commit := range commits {
+		ok, err := filter.matches(repo, commit, classifier)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, commit)
+		}
+	}
+	return matched, nil
+}
+
+// matches checks the cheap fields first, so files are only listed when needed
+func (f CommitFilter) matches(repo *git.Repository, commit *Commit, classifier *Classifier) (bool, error) {
+	header := classifier.Parse(commit.Message)
+
+	if len(f.Types) > 0 && !containsFold(f.Types, header.Type) {
+		return false, nil
+	}
+	if len(f.Scopes) > 0 && !containsFold(f.Scopes, header.Scope) {
+		return false, nil
+	}
+	if len(f.Categories) > 0 && !containsFold(f.Categories, classifier.Categorize(commit).Key()) {
+		return false, nil
+	}
+	if f.Breaking && !header.Breaking {
+		return false, nil
+	}
+	if f.Author != nil && !f.Author.MatchString(fmt.Sprintf("%s <%s>", commit.Author, commit.Email)) {
+		return false, nil
+	}
+	if !f.After.IsZero() && commit.Date.Before(f.After) {
+		return false, nil
+	}
+	if !f.Before.IsZero() && !commit.Date.Before(f.Before) {
+		return false, nil
+	}
+	if f.Grep != nil && !f.Grep.MatchString(commit.Message) {
+		return false, nil
+	}
+	if len(f.Trailers) > 0 {
+		trailers := ParseTrailers(commit.Message)
+		for _, want := range f.Trailers {
+			if !hasTrailer(trailers, want) {
+				return false, nil
+			}
+		}
+	}
+
+	if !f.IncludeExcluded {
+		if _, excluded := classifier.Excluded(commit); excluded {
+			return false, nil
+		}
+	}
+	if len(f.Paths) > 0 {
+		files, err := ChangedFiles(repo, commit.FullHash)
+		if err != nil {
+			return false, err
+		}
+		if !anyPathMatches(f.Paths, files) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// containsFold reports whether value is in the list, ignoring case
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTrailer reports whether a trailer with the key, and the value if one is given, is present
+func hasTrailer(trailers []Trailer, want TrailerFilter) bool {
+	for _, value := range TrailerValues(trailers, want.Key) {
+		if want.Value == "" || strings.Contains(strings.ToLower(value), strings.ToLower(want.Value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// anyPathMatches reports whether any file is matched by a pattern. A pattern
+// matches a file, a directory containing it, or is a glob such as "*.go".
+func anyPathMatches(patterns, files []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(strings.TrimPrefix(pattern, "./"), "/")
+		for _, file := range files {
+			if file == pattern || strings.HasPrefix(file, pattern+"/") {
+				return true
+			}
+			if ok, _ := path.Match(pattern, file); ok {
+				return true
+			}
+			if ok, _ := path.Match(pattern, path.Base(file)); ok && !strings.Contains(pattern, "/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ChangedFiles lists the files a commit changed, compared with its first parent.
+// It diffs the trees rather than the patch, so binary and empty files, mode
+// changes and submodules are listed too; a rename lists both paths.
+func ChangedFiles(repo *git.Repository, hash string) ([]string, error) {
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree of %s: %w", hash, err)
+	}
+
+	// A root commit is compared with an empty tree
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read parent of %s: %w", hash, err)
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, fmt.Errorf("failed to read tree of %s: %w", parent.Hash, err)
+		}
+	}
+
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff commit %s: %w", hash, err)
+	}
+	var files []string
+	seen := make(map[string]bool)
+	for _, change := range changes {
+		for _, name := range []string{change.From.Name, change.To.Name} {
+			if name != "" && !seen[name] {
+				seen[name] = true
+				files = append(files, name)
+			}
+		}
+	}
+	return files, nil
+}
+
+// ResolveRevisionRange resolves "from..to", "from.." or a single revision the way
+// git log reads them. A single revision, or an empty from, covers all of its history.
+func ResolveRevisionRange(repo *git.Repository, spec string) (plumbing.Hash, plumbing.Hash, error) {
+	fromRev, toRev, isRange := strings.Cut(spec, "..")
+	if !isRange {
+		fromRev, toRev = "", spec
+	}
+	if toRev == "" {
+		toRev = "HEAD"
+	}
+
+	from := plumbing.ZeroHash
+	if fromRev != "" {
+		hash, err := ResolveRevision(repo, fromRev)
+		if err != nil {
+			return plumbing.ZeroHash, plumbing.ZeroHash, err
+		}
+		from = hash
+	}
+	to, err := ResolveRevision(repo, toRev)
+	if err != nil {
+		return plumbing.ZeroHash, plumbing.ZeroHash, err
+	}
+	return from, to, nil
+}
@@ -0,0 +1,267 @@
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Changelog is a whole changelog: a title and its releases, newest first
+type Changelog struct {
+	Title    string     `json:"title,omitempty"`
+	Releases []*Release `json:"releases"`
+}
+
+// Release is one version section of a changelog
+type Release struct {
+	Version      string         `json:"version,omitempty"`      // e.g. "1.2.0"; empty for unreleased changes
+	Tag          string         `json:"tag,omitempty"`          // git tag of this release, e.g. "v1.2.0"
+	PreviousTag  string         `json:"previous_tag,omitempty"` // git tag of the release before this one
+	Date         time.Time      `json:"date"`
+	Sections     []*Section     `json:"sections"`
+	Contributors []*Contributor `json:"contributors,omitempty"`
+}
+
+// Section is a group of entries sharing a category
+type Section struct {
+	Category CommitCategory `json:"category"`
+	Title    string         `json:"title,omitempty"` // heading as written in a parsed changelog, e.g. "Added"
+	Entries  []*Entry       `json:"entries"`
+}
+
+// Entry is a single line of a changelog
+type Entry struct {
+	Text     string `json:"text"`
+	Body     string `json:"body,omitempty"` // commit body without trailers; may contain Markdown
+	Hash     string `json:"hash,omitempty"`
+	FullHash string `json:"full_hash,omitempty"`
+	Type     string `json:"type,omitempty"` // conventional commit type, e.g. "feat"
+	Scope    string `json:"scope,omitempty"`
+
+	OriginalText string `json:"-"` // text before an AI rewrite; empty if it wasn't rewritten
+}
+
+// CategoryOrder is the order categories appear in a changelog
+var CategoryOrder = []CommitCategory{
+	CategoryBreaking,
+	CategoryFeature,
+	CategoryFix,
+	CategoryPerformance,
+	CategoryRefactor,
+	CategoryDocs,
+	CategoryTest,
+	CategoryChore,
+	CategoryOther,
+}
+
+// categoryKeys are the stable names used for categories in JSON output
+var categoryKeys = map[CommitCategory]string{
+	CategoryBreaking:    "breaking",
+	CategoryFeature:     "features",
+	CategoryFix:         "fixes",
+	CategoryPerformance: "performance",
+	CategoryRefactor:    "refactoring",
+	CategoryDocs:        "documentation",
+	CategoryTest:        "tests",
+	CategoryChore:       "chores",
+	CategoryOther:       "other",
+}
+
+// Key returns the stable name of the category, e.g. "features"
+func (c CommitCategory) Key() string {
+	if key, ok := categoryKeys[c]; ok {
+		return key
+	}
+	return strings.ToLower(strings.TrimSpace(string(c)))
+}
+
+// MarshalText writes the category as its stable key
+func (c CommitCategory) MarshalText() ([]byte, error) {
+	return []byte(c.Key()), nil
+}
+
+// UnmarshalText reads a category from its stable key
+func (c *CommitCategory) UnmarshalText(text []byte) error {
+	category, ok := CategoryForKey(string(text))
+	if !ok {
+		return fmt.Errorf("unknown category %q", text)
+	}
+	*c = category
+	return nil
+}
+
+// CategoryForKey finds the category with the given stable key
+func CategoryForKey(key string) (CommitCategory, bool) {
+	for category, k := range categoryKeys {
+		if k == key {
+			return category, true
+		}
+	}
+	return "", false
+}
+
+// BuildRelease groups commits into the sections of a release, leaving out the
+// commits the classifier excludes. A nil classifier uses the built-in rules.
+func BuildRelease(commits []*Commit, version string, date time.Time, classifier *Classifier) *Release {
+	release := &Release{Version: version, Date: date}
+	commits, _ = classifier.Exclude(commits)
+	groups := classifier.Group(commits)
+
+	for _, category := range CategoryOrder {
+		categoryCommits, exists := groups[category]
+		if !exists || len(categoryCommits) == 0 {
+			continue // Skip empty categories
+		}
+
+		section := &Section{Category: category}
+		for _, commit := range categoryCommits {
+			header := classifier.Parse(commit.Message)
+			entry := &Entry{
+				Text:     entryText(commit.Message, classifier),
+				Hash:     commit.Hash,
+				FullHash: commit.FullHash,
+				Type:     header.Type,
+				Scope:    header.Scope,
+			}
+			if commit.OriginalMessage != "" {
+				entry.OriginalText = entryText(commit.OriginalMessage, classifier)
+				entry.Body = CommitBody(commit.OriginalMessage) // AI rewrites only the subject
+			} else {
+				entry.Body = CommitBody(commit.Message)
+			}
+			section.Entries = append(section.Entries, entry)
+		}
+		release.Sections = append(release.Sections, section)
+	}
+
+	return release
+}
+
+// IsUnreleased reports whether the release holds changes that haven't been versioned yet
+func (r *Release) IsUnreleased() bool {
+	return r.Version == ""
+}
+
+// EntryCount returns the number of entries across all sections
+func (r *Release) EntryCount() int {
+	count := 0
+	for _, section := range r.Sections {
+		count += len(section.Entries)
+	}
+	return count
+}
+
+// EntryCount returns the number of entries across all releases
+func (c *Changelog) EntryCount() int {
+	count := 0
+	for _, release := range c.Releases {
+		count += release.EntryCount()
+	}
+	return count
+}
+
+// entryText is the changelog text for a commit message. Messages read by a custom
+// parser use the subject it captured, followed by the ticket.
+func entryText(message string, classifier *Classifier) string {
+	header := classifier.Parse(message)
+	if header.Parser == "" {
+		return cleanCommitMessage(message, classifier.gitmojiTable())
+	}
+	text := cleanCommitMessage(header.Subject, classifier.gitmojiTable())
+	if header.Ticket != "" {
+		text += " (" + header.Ticket + ")"
+	}
+	return text
+}
+
+// conventionalPrefix matches a leading "type(scope)!: " prefix
+var conventionalPrefix = regexp.MustCompile(`^(feat|fix|docs|chore|test|refactor|perf)(\([^)]*\))?!?:\s*`)
+
+// CleanCommitMessage removes conventional commit prefixes and keeps only the subject line
+func CleanCommitMessage(msg string) string {
+	return cleanCommitMessage(msg, builtinGitmojiTable)
+}
+
+// cleanCommitMessage cleans a message, removing the gitmoji in table
+func cleanCommitMessage(msg string, table map[string]CommitCategory) string {
+	// Remove trailing newlines
+	cleaned := ""
+	for _, c := range msg {
+		if c == '\n' {
+			break
+		}
+		cleaned += string(c)
+	}
+
+	// Remove conventional commit prefix with optional scope (feat:, fix(api):, feat!:)
+	// Only the leading prefix is removed so references like "(#12)" survive.
+	// A gitmoji before or after the prefix goes too ("✨ feat: ...", "feat: :sparkles: ...").
+	_, cleaned = splitGitmoji(cleaned, table)
+	result := conventionalPrefix.ReplaceAllString(cleaned, "")
+	_, result = splitGitmoji(result, table)
+
+	// Capitalize first letter
+	if len(result) > 0 {
+		first := result[0]
+		if first >= 'a' && first <= 'z' {
+			result = string(first-32) + result[1:]
+		}
+	}
+
+	return result
+}
+
+// InsertReleaseSection adds a rendered release section to an existing changelog,
+// above the newest release. header is used when the changelog is empty.
+func InsertReleaseSection(existing, header, section string) string {
+	if strings.TrimSpace(existing) == "" {
+		return header + section
+	}
+
+	lines := strings.SplitAfter(existing, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "## ") {
+			return strings.Join(lines[:i], "") + section + strings.Join(lines[i:], "")
+		}
+	}
+
+	// No releases yet, so add the section at the end
+	if !strings.HasSuffix(existing, "\n") {
+		existing += "\n"
+	}
+	return existing + "\n" + section
+}
+
+// releaseHeading captures the version from headings like "## Version 1.2.0", "## Versión 1.2.0"
+// or "## [1.2.0] - 2026-10-01"
+var releaseHeading = regexp.MustCompile(`^## (?:[^\s\d\[]+ )?\[?([^\s\]]+)`)
+
+// RemovePreReleaseSections drops the sections of pre-releases of the given final version,
+// so that "1.3.0-rc.1" and "1.3.0-rc.2" are rolled up into the "1.3.0" section
+func RemovePreReleaseSections(content string, final *Version, scheme VersionScheme, prefix string) string {
+	lines := strings.SplitAfter(content, "\n")
+	out := ""
+	skipping := false
+
+	for _, line := range lines {
+		if strings.HasPrefix(line, "## ") {
+			skipping = false
+			if match := releaseHeading.FindStringSubmatch(line); match != nil {
+				version, err := scheme.Parse(strings.TrimPrefix(match[1], prefix))
+				if err == nil && version.IsPreRelease() &&
+					version.Major == final.Major && version.Minor == final.Minor && version.Patch == final.Patch {
+					skipping = true
+				}
+			}
+		} else if skipping && strings.HasPrefix(line, "---") {
+			// The footer ends the last release section
+			skipping = false
+		}
+
+		if !skipping {
+			out += line
+		}
+	}
+	return out
+}
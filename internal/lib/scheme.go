@@ -0,0 +1,263 @@
+package lib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VersionScheme parses, orders and increments versions of one style (SemVer or CalVer)
+type VersionScheme interface {
+	Name() string
+	Parse(s string) (*Version, error)
+	Compare(a, b *Version) int
+	Next(previous *Version, bump BumpType, now time.Time) (*Version, error)
+	Channels() []string
+}
+
+// NewVersionScheme builds the version scheme described by the config
+func NewVersionScheme(config *Config) (VersionScheme, error) {
+	channels := config.Versioning.Channels
+
+	switch strings.ToLower(config.Versioning.Scheme) {
+	case "", "semver":
+		return &semverScheme{channels: channels}, nil
+	case "calver":
+		return newCalverScheme(config.Versioning.CalverFormat, channels)
+	}
+	return nil, fmt.Errorf("unknown version scheme %q (use semver or calver)", config.Versioning.Scheme)
+}
+
+// compareVersions orders versions, ranking pre-release channels by their configured
+// order (e.g. alpha < beta < rc) before falling back to semver precedence
+func compareVersions(a, b *Version, channels []string) int {
+	if len(a.PreRelease) > 0 && len(b.PreRelease) > 0 &&
+		a.Major == b.Major && a.Minor == b.Minor && a.Patch == b.Patch {
+		ia, ib := channelIndex(channels, a.PreRelease[0]), channelIndex(channels, b.PreRelease[0])
+		if ia != -1 && ib != -1 && ia != ib {
+			return compareInts(ia, ib)
+		}
+	}
+	return a.Compare(b)
+}
+
+// channelIndex returns the position of a channel in the configured list, or -1
+func channelIndex(channels []string, channel string) int {
+	for i, c := range channels {
+		if c == channel {
+			return i
+		}
+	}
+	return -1
+}
+
+// semverScheme is MAJOR.MINOR.PATCH with optional pre-release channels
+type semverScheme struct {
+	channels []string
+}
+
+func (s *semverScheme) Name() string       { return "semver" }
+func (s *semverScheme) Channels() []string { return s.channels }
+
+func (s *semverScheme) Parse(v string) (*Version, error) {
+	return ParseVersion(v)
+}
+
+func (s *semverScheme) Compare(a, b *Version) int {
+	return compareVersions(a, b, s.channels)
+}
+
+func (s *semverScheme) Next(previous *Version, bump BumpType, now time.Time) (*Version, error) {
+	if previous == nil {
+		return ParseVersion("0.1.0")
+	}
+	return previous.Bump(bump), nil
+}
+
+// CalVer format tokens (https://calver.org)
+var calverTokens = map[string]bool{
+	"YYYY": true, "YY": true, "0Y": true,
+	"MM": true, "0M": true,
+	"WW": true, "0W": true,
+	"DD": true, "0D": true,
+	"MAJOR": true, "MINOR": true, "MICRO": true,
+}
+
+// calverScheme is a calendar version such as YYYY.0M.MICRO (2026.10.1)
+type calverScheme struct {
+	layout   []string
+	channels []string
+}
+
+func newCalverScheme(format string, channels []string) (*calverScheme, error) {
+	if format == "" {
+		format = "YYYY.0M.MICRO"
+	}
+
+	layout := strings.Split(format, ".")
+	if len(layout) != 3 {
+		return nil, fmt.Errorf("calver format %q must have three segments", format)
+	}
+	for _, token := range layout {
+		if !calverTokens[token] {
+			return nil, fmt.Errorf("unknown calver token %q in %q", token, format)
+		}
+	}
+
+	return &calverScheme{layout: layout, channels: channels}, nil
+}
+
+func (s *calverScheme) Name() string       { return "calver" }
+func (s *calverScheme) Channels() []string { return s.channels }
+
+func (s *calverScheme) Parse(v string) (*Version, error) {
+	version := &Version{layout: s.layout}
+	rest := v
+
+	if i := strings.Index(rest, "+"); i != -1 {
+		version.Build = rest[i+1:]
+		rest = rest[:i]
+	}
+	if i := strings.Index(rest, "-"); i != -1 {
+		if rest[i+1:] == "" {
+			return nil, fmt.Errorf("invalid version %q: empty pre-release", v)
+		}
+		version.PreRelease = strings.Split(rest[i+1:], ".")
+		rest = rest[:i]
+	}
+
+	parts := strings.Split(rest, ".")
+	if len(parts) != len(s.layout) {
+		return nil, fmt.Errorf("invalid version %q: expected %s", v, strings.Join(s.layout, "."))
+	}
+
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := parseCalverSegment(s.layout[i], part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q: %w", v, err)
+		}
+		numbers[i] = n
+	}
+	version.Major, version.Minor, version.Patch = numbers[0], numbers[1], numbers[2]
+
+	return version, nil
+}
+
+// parseCalverSegment checks a segment against its token's width and range
+func parseCalverSegment(token, part string) (int, error) {
+	n, err := strconv.Atoi(part)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("bad %s %q", token, part)
+	}
+
+	padded := strings.HasPrefix(token, "0")
+	switch {
+	case token == "YYYY" && len(part) != 4:
+		return 0, fmt.Errorf("bad %s %q", token, part)
+	case padded && len(part) != 2:
+		return 0, fmt.Errorf("bad %s %q", token, part)
+	case !padded && token != "YYYY" && len(part) > 1 && part[0] == '0':
+		return 0, fmt.Errorf("bad %s %q", token, part)
+	case (token == "MM" || token == "0M") && (n < 1 || n > 12):
+		return 0, fmt.Errorf("bad month %q", part)
+	case (token == "DD" || token == "0D") && (n < 1 || n > 31):
+		return 0, fmt.Errorf("bad day %q", part)
+	}
+	return n, nil
+}
+
+func (s *calverScheme) Compare(a, b *Version) int {
+	return compareVersions(a, b, s.channels)
+}
+
+// Next uses today's date. Counters (MAJOR, MINOR, MICRO) increment when the date
+// part is unchanged and reset when it moves on.
+func (s *calverScheme) Next(previous *Version, bump BumpType, now time.Time) (*Version, error) {
+	format := strings.Join(s.layout, ".")
+	hasMicro := strings.Contains(format, "MICRO")
+
+	// Week numbers belong to the ISO year, which differs from the calendar year around New Year
+	year, week := now.ISOWeek()
+	if !strings.Contains(format, "W") {
+		year = now.Year()
+	}
+	dates := map[string]int{
+		"YYYY": year, "YY": year - 2000, "0Y": year - 2000,
+		"MM": int(now.Month()), "0M": int(now.Month()),
+		"WW": week, "0W": week,
+		"DD": now.Day(), "0D": now.Day(),
+	}
+
+	var prev []int
+	if previous != nil {
+		prev = []int{previous.Major, previous.Minor, previous.Patch}
+	}
+
+	// Has the date part moved on since the previous release?
+	sameDate := previous != nil
+	for i, token := range s.layout {
+		if value, isDate := dates[token]; isDate && prev != nil && prev[i] != value {
+			sameDate = false
+		}
+	}
+
+	next := make([]int, 3)
+	bumped := false
+	for i, token := range s.layout {
+		if value, isDate := dates[token]; isDate {
+			next[i] = value
+			continue
+		}
+
+		// Counters
+		previousValue := 0
+		if prev != nil {
+			previousValue = prev[i]
+		}
+		switch {
+		case bumped:
+			next[i] = 0 // lower counters reset after a higher one moved
+		case token == "MAJOR":
+			next[i] = previousValue
+			if previous != nil && bump == BumpMajor {
+				next[i]++
+				bumped = true
+			}
+		case !sameDate:
+			next[i] = 0
+		case token == "MINOR" && (bump != BumpPatch || !hasMicro):
+			next[i] = previousValue + 1
+			bumped = true
+		case token == "MICRO":
+			next[i] = previousValue + 1
+			bumped = true
+		default:
+			next[i] = previousValue
+		}
+	}
+
+	if sameDate && !bumped {
+		return nil, fmt.Errorf("calver format %s has no counter to release twice on the same date", format)
+	}
+
+	return &Version{Major: next[0], Minor: next[1], Patch: next[2], layout: s.layout}, nil
+}
+
+// formatCalverSegment renders a segment with the zero padding its token asks for
+func formatCalverSegment(token string, n int) string {
+	if strings.HasPrefix(token, "0") {
+		return fmt.Sprintf("%02d", n)
+	}
+	return strconv.Itoa(n)
+}
+
+// ValidateChannel checks that a pre-release channel is one of the configured ones
+func ValidateChannel(scheme VersionScheme, channel string) error {
+	channels := scheme.Channels()
+	if channel == "" || len(channels) == 0 || channelIndex(channels, channel) != -1 {
+		return nil
+	}
+	return fmt.Errorf("unknown pre-release channel %q (configured: %s)", channel, strings.Join(channels, ", "))
+}
@@ -0,0 +1,365 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Version is a semantic version (https://semver.org)
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease []string // dot-separated identifiers, e.g. ["rc", "1"]
+	Build      string
+
+	layout []string // CalVer tokens for formatting; nil for SemVer
+}
+
+// BumpType is the kind of version increment a set of commits calls for
+type BumpType string
+
+const (
+	BumpMajor BumpType = "major"
+	BumpMinor BumpType = "minor"
+	BumpPatch BumpType = "patch"
+)
+
+// ParseVersion parses a semantic version like "1.2.3-rc.1+build.5"
+func ParseVersion(s string) (*Version, error) {
+	v := &Version{}
+	rest := s
+
+	if i := strings.Index(rest, "+"); i != -1 {
+		v.Build = rest[i+1:]
+		rest = rest[:i]
+	}
+	if i := strings.Index(rest, "-"); i != -1 {
+		pre := rest[i+1:]
+		if pre == "" {
+			return nil, fmt.Errorf("invalid version %q: empty pre-release", s)
+		}
+		v.PreRelease = strings.Split(pre, ".")
+		rest = rest[:i]
+	}
+
+	parts := strings.Split(rest, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid version %q: expected MAJOR.MINOR.PATCH", s)
+	}
+
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || (len(part) > 1 && part[0] == '0') {
+			return nil, fmt.Errorf("invalid version %q: bad number %q", s, part)
+		}
+		numbers[i] = n
+	}
+	v.Major, v.Minor, v.Patch = numbers[0], numbers[1], numbers[2]
+
+	return v, nil
+}
+
+// String formats the version without any tag prefix
+func (v *Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.layout != nil {
+		s = formatCalverSegment(v.layout[0], v.Major) + "." +
+			formatCalverSegment(v.layout[1], v.Minor) + "." +
+			formatCalverSegment(v.layout[2], v.Patch)
+	}
+	if len(v.PreRelease) > 0 {
+		s += "-" + strings.Join(v.PreRelease, ".")
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// IsPreRelease reports whether the version has pre-release identifiers
+func (v *Version) IsPreRelease() bool {
+	return len(v.PreRelease) > 0
+}
+
+// Compare returns -1, 0 or 1 following semver precedence rules (build metadata is ignored)
+func (v *Version) Compare(other *Version) int {
+	for _, pair := range [][2]int{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if pair[0] != pair[1] {
+			return compareInts(pair[0], pair[1])
+		}
+	}
+
+	// A release has higher precedence than any of its pre-releases
+	switch {
+	case len(v.PreRelease) == 0 && len(other.PreRelease) == 0:
+		return 0
+	case len(v.PreRelease) == 0:
+		return 1
+	case len(other.PreRelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.PreRelease) && i < len(other.PreRelease); i++ {
+		if c := comparePreReleaseIdentifier(v.PreRelease[i], other.PreRelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(v.PreRelease), len(other.PreRelease))
+}
+
+// comparePreReleaseIdentifier compares numerically when both are numbers, otherwise lexically
+func comparePreReleaseIdentifier(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return compareInts(na, nb)
+	case errA == nil:
+		return -1 // numeric identifiers sort before alphanumeric ones
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Bump returns the next release version for the given increment.
+// Pre-release identifiers and build metadata are dropped.
+func (v *Version) Bump(bump BumpType) *Version {
+	next := &Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch, layout: v.layout}
+	switch bump {
+	case BumpMajor:
+		next.Major++
+		next.Minor = 0
+		next.Patch = 0
+	case BumpMinor:
+		next.Minor++
+		next.Patch = 0
+	default:
+		next.Patch++
+	}
+	return next
+}
+
+// WithPreRelease returns v with a "-<id>.N" suffix. N continues from latest
+// when latest is a pre-release of the same version and identifier.
+func (v *Version) WithPreRelease(id string, latest *Version) *Version {
+	next := &Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch, layout: v.layout}
+	number := 1
+
+	if latest != nil && latest.Major == v.Major && latest.Minor == v.Minor && latest.Patch == v.Patch &&
+		len(latest.PreRelease) == 2 && latest.PreRelease[0] == id {
+		if n, err := strconv.Atoi(latest.PreRelease[1]); err == nil {
+			number = n + 1
+		}
+	}
+
+	next.PreRelease = []string{id, strconv.Itoa(number)}
+	return next
+}
+
+// DetermineBump decides the increment for a set of commits. Breaking changes (a
+// "type!:" subject or a BREAKING CHANGE footer) bump major, features bump minor,
+// anything else bumps patch.
+// While the major version is 0, everything moves down one level (breaking bumps minor).
+func DetermineBump(current *Version, commits []*Commit, classifier *Classifier) BumpType {
+	bump := BumpPatch
+	for _, commit := range commits {
+		switch {
+		case classifier.Parse(commit.Message).Breaking:
+			bump = BumpMajor
+		case classifier.Categorize(commit) == CategoryFeature:
+			if bump == BumpPatch {
+				bump = BumpMinor
+			}
+		}
+	}
+
+	if current != nil && current.Major == 0 {
+		switch bump {
+		case BumpMajor:
+			bump = BumpMinor
+		case BumpMinor:
+			bump = BumpPatch
+		}
+	}
+	return bump
+}
+
+// VersionTag is a git tag that holds a version
+type VersionTag struct {
+	Name    string
+	Version *Version
+	Hash    plumbing.Hash // commit the tag points to
+	Date    time.Time     // tagger date for annotated tags, commit date otherwise
+}
+
+// FindVersionTags returns all tags of the form <prefix><version>, newest version first
+func FindVersionTags(repo *git.Repository, prefix string, scheme VersionScheme) ([]*VersionTag, error) {
+	refs, err := repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	var tags []*VersionTag
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+		version, err := scheme.Parse(strings.TrimPrefix(name, prefix))
+		if err != nil {
+			return nil // not a version tag
+		}
+
+		// Annotated tags point to a tag object, lightweight tags point to the commit
+		tag := &VersionTag{Name: name, Version: version, Hash: ref.Hash()}
+		if tagObject, err := repo.TagObject(ref.Hash()); err == nil {
+			commit, err := tagObject.Commit()
+			if err != nil {
+				return nil // tag of something other than a commit
+			}
+			tag.Hash = commit.Hash
+			tag.Date = tagObject.Tagger.When
+		} else {
+			commit, err := repo.CommitObject(ref.Hash())
+			if err != nil {
+				return nil
+			}
+			tag.Date = commit.Committer.When
+		}
+
+		tags = append(tags, tag)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return scheme.Compare(tags[i].Version, tags[j].Version) > 0
+	})
+	return tags, nil
+}
+
+// LatestVersionTag returns the highest version tag, optionally skipping pre-releases.
+// It returns nil when there is no matching tag.
+func LatestVersionTag(tags []*VersionTag, includePreReleases bool) *VersionTag {
+	for _, tag := range tags {
+		if includePreReleases || !tag.Version.IsPreRelease() {
+			return tag
+		}
+	}
+	return nil
+}
+
+// NextVersionOptions controls how the next version is calculated
+type NextVersionOptions struct {
+	TagPrefix  string        // e.g. "v"
+	Scheme     VersionScheme // defaults to SemVer
+	PreRelease string        // pre-release channel such as "rc"; empty for a final release
+	Initial    string        // version to use when the repository has no version tags yet
+	Classifier *Classifier   // categorizes the commits; nil for the built-in rules
+}
+
+// NextVersionResult describes the calculated next version
+type NextVersionResult struct {
+	Previous *VersionTag // latest final release, nil if none
+	Latest   *VersionTag // latest tag including pre-releases, nil if none
+	Next     *Version
+	Bump     BumpType
+	Commits  []*Commit        // commits since the previous final release, without the excluded ones
+	Excluded []ExcludedCommit // commits since then that the exclusion rules leave out
+}
+
+// CalculateNextVersion finds the latest version tag and works out the next version
+// from the commits made since then
+func CalculateNextVersion(repo *git.Repository, opts NextVersionOptions) (*NextVersionResult, error) {
+	scheme := opts.Scheme
+	if scheme == nil {
+		scheme = &semverScheme{}
+	}
+	if err := ValidateChannel(scheme, opts.PreRelease); err != nil {
+		return nil, err
+	}
+
+	tags, err := FindVersionTags(repo, opts.TagPrefix, scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &NextVersionResult{
+		Previous: LatestVersionTag(tags, false),
+		Latest:   LatestVersionTag(tags, true),
+	}
+
+	// Pre-releases are rolled into the next final release, so count from the last final one
+	since := plumbing.ZeroHash
+	if result.Previous != nil {
+		since = result.Previous.Hash
+	}
+	result.Commits, err = GetCommitsSince(repo, since)
+	if err != nil {
+		return nil, err
+	}
+
+	// Excluded commits don't go into the changelog, so they don't bump the version either
+	result.Commits, result.Excluded = opts.Classifier.Exclude(result.Commits)
+	if len(result.Commits) == 0 {
+		since := "found"
+		if result.Previous != nil {
+			since = "since " + result.Previous.Name
+		}
+		if len(result.Excluded) > 0 {
+			return nil, fmt.Errorf("no commits %s besides %d excluded ones", since, len(result.Excluded))
+		}
+		return nil, fmt.Errorf("no commits %s", since)
+	}
+
+	switch {
+	case result.Previous == nil && opts.Initial != "":
+		result.Next, err = scheme.Parse(opts.Initial)
+		result.Bump = DetermineBump(result.Next, result.Commits, opts.Classifier)
+	case result.Previous == nil:
+		result.Next, err = scheme.Next(nil, BumpMinor, time.Now())
+		result.Bump = DetermineBump(result.Next, result.Commits, opts.Classifier)
+	default:
+		result.Bump = DetermineBump(result.Previous.Version, result.Commits, opts.Classifier)
+		result.Next, err = scheme.Next(result.Previous.Version, result.Bump, time.Now())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.PreRelease != "" {
+		var latest *Version
+		if result.Latest != nil {
+			latest = result.Latest.Version
+		}
+		result.Next = result.Next.WithPreRelease(opts.PreRelease, latest)
+
+		// Channels only move forward (alpha -> beta -> rc) within a version
+		if latest != nil && scheme.Compare(result.Next, latest) < 0 {
+			return nil, fmt.Errorf("%s would come before the existing %s", result.Next, latest)
+		}
+	}
+
+	return result, nil
+}
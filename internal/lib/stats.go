@@ -0,0 +1,133 @@
+package lib
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// ReleaseStats summarizes the commits of one release or commit range
+type ReleaseStats struct {
+	Version             string         `json:"version"` // empty for unreleased commits
+	Date                time.Time      `json:"date"`
+	Commits             int            `json:"commits"`
+	Conventional        int            `json:"conventional"` // commits with a conventional header of a known type
+	ConventionalPercent float64        `json:"conventional_percent"`
+	Categories          map[string]int `json:"categories"` // by category key, e.g. "features"
+	Scopes              map[string]int `json:"scopes"`
+	Authors             map[string]int `json:"authors"`
+	Lines               *LineStats     `json:"lines,omitempty"` // nil unless lines were counted
+	Trend               *StatsTrend    `json:"trend,omitempty"` // nil for the oldest release
+}
+
+// LineStats are the lines a range of commits added and deleted
+type LineStats struct {
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
+}
+
+// StatsTrend compares a release with the one before it
+type StatsTrend struct {
+	Commits             int     `json:"commits"`                 // change in the number of commits
+	ConventionalPercent float64 `json:"conventional_percent"`    // change in percentage points
+	LinesChanged        *int    `json:"lines_changed,omitempty"` // change in additions plus deletions, if counted
+}
+
+// LinesChanged is the number of added and deleted lines, or 0 if they weren't counted
+func (s *ReleaseStats) LinesChanged() int {
+	if s.Lines == nil {
+		return 0
+	}
+	return s.Lines.Additions + s.Lines.Deletions
+}
+
+// CollectStats computes the statistics of each range. Ranges are newest first,
+// as ListReleaseRanges returns them; each release is compared with the next one.
+// Authors are resolved through the mailmap, which may be nil. Counting lines
+// diffs every commit, which is slow on a long history, so it's only done if lines is set.
+func CollectStats(repo *git.Repository, ranges []*ReleaseRange, mailmap *Mailmap, classifier *Classifier, lines bool) ([]*ReleaseStats, error) {
+	var all []*ReleaseStats
+	for _, r := range ranges {
+		// Excluded commits are noise to the statistics as well as the changelog
+		commits, _ := classifier.Exclude(r.Commits)
+		stats := &ReleaseStats{
+			Version:    r.Version,
+			Date:       r.Date,
+			Commits:    len(commits),
+			Categories: make(map[string]int),
+			Scopes:     make(map[string]int),
+			Authors:    make(map[string]int),
+		}
+		if lines {
+			stats.Lines = &LineStats{}
+		}
+
+		for _, commit := range commits {
+			// Only conventional headers with a known type count, not "WIP:" or
+			// headers read by custom parsers
+			if header := ParseCommitHeader(commit.Message); header.Type != "" && classifier.IsCommitType(header.Type) {
+				stats.Conventional++
+			}
+			header := classifier.Parse(commit.Message)
+			if header.Scope != "" {
+				stats.Scopes[header.Scope]++
+			}
+			stats.Categories[classifier.Categorize(commit).Key()]++
+
+			name, _ := mailmap.Resolve(commit.Author, commit.Email)
+			stats.Authors[name]++
+
+			if stats.Lines != nil {
+				additions, deletions, err := commitLineCounts(repo, commit.FullHash)
+				if err != nil {
+					return nil, err
+				}
+				stats.Lines.Additions += additions
+				stats.Lines.Deletions += deletions
+			}
+		}
+		if stats.Commits > 0 {
+			stats.ConventionalPercent = float64(stats.Conventional) * 100 / float64(stats.Commits)
+		}
+		all = append(all, stats)
+	}
+
+	for i := 0; i+1 < len(all); i++ {
+		current, previous := all[i], all[i+1]
+		current.Trend = &StatsTrend{
+			Commits:             current.Commits - previous.Commits,
+			ConventionalPercent: current.ConventionalPercent - previous.ConventionalPercent,
+		}
+		if lines {
+			change := current.LinesChanged() - previous.LinesChanged()
+			current.Trend.LinesChanged = &change
+		}
+	}
+
+	return all, nil
+}
+
+// commitLineCounts returns the lines a commit added and deleted.
+// Merge commits count as zero, since their changes are counted in the merged commits.
+func commitLineCounts(repo *git.Repository, hash string) (int, int, error) {
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+	if commit.NumParents() > 1 {
+		return 0, 0, nil
+	}
+
+	files, err := commit.Stats()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to diff commit %s: %w", hash, err)
+	}
+	additions, deletions := 0, 0
+	for _, file := range files {
+		additions += file.Addition
+		deletions += file.Deletion
+	}
+	return additions, deletions, nil
+}
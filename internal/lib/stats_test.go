@@ -0,0 +1,29 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestCollectStatsCountsKnownTypes(t *testing.T) {
+	repo, err := git.PlainInit(t.TempDir(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var commits []*Commit
+	for i, message := range []string{"feat: dark mode", "WIP: stuff", "Note: remember this", "ci: cache modules", "Update the readme"} {
+		commits = append(commits, commitFiles(t, repo, message, map[string][]byte{"file.txt": []byte{byte('a' + i)}}))
+	}
+
+	stats, err := CollectStats(repo, []*ReleaseRange{{Version: "1.0.0", Commits: commits}}, nil, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := stats[0].Conventional; got != 2 {
+		t.Errorf("Conventional = %d, want 2", got)
+	}
+	if got := stats[0].ConventionalPercent; got != 40 {
+		t.Errorf("ConventionalPercent = %v, want 40", got)
+	}
+}
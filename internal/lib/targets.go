@@ -0,0 +1,327 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VersionTarget is a place in a project file that holds the version.
+// Exactly one of Pattern, JSONPath or YAMLPath is set.
+type VersionTarget struct {
+	Path     string `yaml:"path"`
+	Pattern  string `yaml:"pattern"`   // regex; the "version" group (or group 1) is replaced
+	JSONPath string `yaml:"json_path"` // dotted path such as "version" or "packages.0.version"
+	YAMLPath string `yaml:"yaml_path"` // dotted path such as "appVersion"
+}
+
+// TargetChange is the planned update of one file
+type TargetChange struct {
+	Path     string
+	Original string
+	Updated  string
+	Found    []string // version strings that were replaced, one per target
+	Created  bool     // the file doesn't exist yet, so restoring it removes it
+
+	mode os.FileMode // permissions of the original file, kept when it's replaced or restored
+}
+
+// PlanVersionTargets works out the new content of every target file without writing anything.
+// Each target must match exactly once, otherwise nothing is changed.
+func PlanVersionTargets(targets []VersionTarget, version string) ([]*TargetChange, error) {
+	var changes []*TargetChange
+	byPath := make(map[string]*TargetChange)
+
+	for _, target := range targets {
+		change, exists := byPath[target.Path]
+		if !exists {
+			data, err := os.ReadFile(target.Path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", target.Path, err)
+			}
+			change = &TargetChange{Path: target.Path, Original: string(data), Updated: string(data)}
+			byPath[target.Path] = change
+			changes = append(changes, change)
+		}
+
+		// Several targets can point at the same file, so apply them one after another
+		start, end, err := locateVersion(change.Updated, target)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", target.Path, err)
+		}
+		change.Found = append(change.Found, change.Updated[start:end])
+		change.Updated = change.Updated[:start] + version + change.Updated[end:]
+	}
+
+	return changes, nil
+}
+
+// ApplyTargetChanges writes all planned changes. Files are written to temporary
+// files first and renamed into place; if anything fails the originals are
+// restored, and any file that couldn't be restored is named in the error.
+func ApplyTargetChanges(changes []*TargetChange) error {
+	var temps []string
+	cleanup := func() {
+		for _, temp := range temps {
+			os.Remove(temp)
+		}
+	}
+
+	for _, change := range changes {
+		change.mode = 0644
+		if info, err := os.Stat(change.Path); err == nil {
+			change.mode = info.Mode().Perm()
+		}
+		temp := change.Path + ".changelog-tmp"
+		if err := os.WriteFile(temp, []byte(change.Updated), change.mode); err != nil {
+			cleanup()
+			return fmt.Errorf("failed to write %s: %w", temp, err)
+		}
+		temps = append(temps, temp)
+	}
+
+	for i, change := range changes {
+		if err := os.Rename(temps[i], change.Path); err != nil {
+			// Put back the files we already replaced
+			restoreErr := RestoreTargetChanges(changes[:i])
+			cleanup()
+			return errors.Join(fmt.Errorf("failed to replace %s: %w", change.Path, err), restoreErr)
+		}
+	}
+	return nil
+}
+
+// RestoreTargetChanges puts back the original contents and permissions of files
+// written by ApplyTargetChanges, for when the release fails after they were written
+func RestoreTargetChanges(changes []*TargetChange) error {
+	var errs []error
+	for _, change := range changes {
+		mode := change.mode
+		if mode == 0 {
+			mode = 0644
+		}
+		if change.Created {
+			if err := os.Remove(change.Path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("failed to remove %s: %w", change.Path, err))
+			}
+			continue
+		}
+		if err := os.WriteFile(change.Path, []byte(change.Original), mode); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", change.Path, err))
+			continue
+		}
+		// WriteFile only applies the mode to new files
+		if err := os.Chmod(change.Path, mode); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore the mode of %s: %w", change.Path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// locateVersion returns the byte range of the version a target points at
+func locateVersion(content string, target VersionTarget) (int, int, error) {
+	switch {
+	case target.Pattern != "":
+		return locateRegex(content, target.Pattern)
+	case target.JSONPath != "":
+		return locateJSON(content, target.JSONPath)
+	case target.YAMLPath != "":
+		return locateYAML(content, target.YAMLPath)
+	}
+	return 0, 0, fmt.Errorf("target needs a pattern, json_path or yaml_path")
+}
+
+// locateRegex finds the version captured by a regular expression (multi-line mode)
+func locateRegex(content, pattern string) (int, int, error) {
+	re, err := regexp.Compile("(?m)" + pattern)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	matches := re.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) != 1 {
+		return 0, 0, fmt.Errorf("pattern %q matched %d times, expected exactly once", pattern, len(matches))
+	}
+
+	group := 0
+	if i := re.SubexpIndex("version"); i != -1 {
+		group = i
+	} else if re.NumSubexp() > 0 {
+		group = 1
+	}
+
+	match := matches[0]
+	if match[2*group] == -1 {
+		return 0, 0, fmt.Errorf("pattern %q matched but its version group is empty", pattern)
+	}
+	return match[2*group], match[2*group+1], nil
+}
+
+// jsonFrame tracks our position inside a JSON object or array
+type jsonFrame struct {
+	object    bool
+	expectKey bool
+	key       string
+	index     int
+}
+
+// locateJSON finds the string value at a dotted path, keeping the file's formatting intact
+func locateJSON(content, path string) (int, int, error) {
+	decoder := json.NewDecoder(strings.NewReader(content))
+	var stack []*jsonFrame
+	var found [][2]int
+
+	// currentPath is the path of the value about to be read
+	currentPath := func() string {
+		parts := make([]string, len(stack))
+		for i, frame := range stack {
+			if frame.object {
+				parts[i] = frame.key
+			} else {
+				parts[i] = strconv.Itoa(frame.index)
+			}
+		}
+		return strings.Join(parts, ".")
+	}
+	// valueDone moves the parent on to its next key or element
+	valueDone := func() {
+		if len(stack) == 0 {
+			return
+		}
+		top := stack[len(stack)-1]
+		if top.object {
+			top.expectKey = true
+		} else {
+			top.index++
+		}
+	}
+
+	for {
+		before := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid JSON: %w", err)
+		}
+		end := decoder.InputOffset()
+
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if top.object && top.expectKey {
+				if key, ok := token.(string); ok {
+					top.key = key
+					top.expectKey = false
+					continue
+				}
+			}
+		}
+
+		switch t := token.(type) {
+		case json.Delim:
+			switch t {
+			case '{':
+				stack = append(stack, &jsonFrame{object: true, expectKey: true})
+			case '[':
+				stack = append(stack, &jsonFrame{})
+			default:
+				stack = stack[:len(stack)-1]
+				valueDone()
+			}
+		case string:
+			if currentPath() == path {
+				// The raw token may be preceded by whitespace, ':' or ','
+				quote := strings.Index(content[before:end], `"`)
+				found = append(found, [2]int{int(before) + quote + 1, int(end) - 1})
+			}
+			valueDone()
+		default:
+			if currentPath() == path {
+				return 0, 0, fmt.Errorf("json_path %q is not a string", path)
+			}
+			valueDone()
+		}
+	}
+
+	if len(found) != 1 {
+		return 0, 0, fmt.Errorf("json_path %q matched %d times, expected exactly once", path, len(found))
+	}
+	return found[0][0], found[0][1], nil
+}
+
+// locateYAML finds the scalar at a dotted path using the YAML node positions
+func locateYAML(content, path string) (int, int, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &root); err != nil {
+		return 0, 0, fmt.Errorf("invalid YAML: %w", err)
+	}
+
+	var found []*yaml.Node
+	var walk func(node *yaml.Node, parts []string)
+	walk = func(node *yaml.Node, parts []string) {
+		if node.Kind == yaml.DocumentNode {
+			for _, child := range node.Content {
+				walk(child, parts)
+			}
+			return
+		}
+		if len(parts) == 0 {
+			found = append(found, node)
+			return
+		}
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == parts[0] {
+					walk(node.Content[i+1], parts[1:])
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(parts[0]); err == nil && i >= 0 && i < len(node.Content) {
+				walk(node.Content[i], parts[1:])
+			}
+		}
+	}
+	walk(&root, strings.Split(path, "."))
+
+	if len(found) != 1 {
+		return 0, 0, fmt.Errorf("yaml_path %q matched %d times, expected exactly once", path, len(found))
+	}
+	node := found[0]
+	if node.Kind != yaml.ScalarNode {
+		return 0, 0, fmt.Errorf("yaml_path %q is not a scalar", path)
+	}
+
+	// Convert the node's line and column into a byte offset
+	offset := 0
+	for line := 1; line < node.Line; line++ {
+		next := strings.Index(content[offset:], "\n")
+		if next == -1 {
+			return 0, 0, fmt.Errorf("yaml_path %q: position out of range", path)
+		}
+		offset += next + 1
+	}
+	start := offset + node.Column - 1
+
+	switch node.Style {
+	case yaml.DoubleQuotedStyle, yaml.SingleQuotedStyle:
+		// Keep the quotes, replace what's between them
+		start++
+		if strings.HasPrefix(content[start:], node.Value) {
+			return start, start + len(node.Value), nil
+		}
+	case 0:
+		if strings.HasPrefix(content[start:], node.Value) {
+			return start, start + len(node.Value), nil
+		}
+	}
+	return 0, 0, fmt.Errorf("yaml_path %q uses a scalar style that can't be rewritten in place", path)
+}
@@ -0,0 +1,62 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyTargetChangesRestoresOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "version.sh")
+	if err := os.WriteFile(script, []byte("echo 1.0.0\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// A directory can't be replaced by a file, so the second rename fails
+	blocked := filepath.Join(dir, "blocked")
+	if err := os.MkdirAll(filepath.Join(blocked, "inside"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := []*TargetChange{
+		{Path: script, Original: "echo 1.0.0\n", Updated: "echo 1.1.0\n"},
+		{Path: blocked, Updated: "1.1.0\n"},
+	}
+	err := ApplyTargetChanges(changes)
+	if err == nil || !strings.Contains(err.Error(), "failed to replace "+blocked) {
+		t.Fatalf("ApplyTargetChanges() error = %v, want a failed replace of %s", err, blocked)
+	}
+
+	data, err := os.ReadFile(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "echo 1.0.0\n" {
+		t.Errorf("%s = %q, want the original content", script, data)
+	}
+	info, err := os.Stat(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o755 {
+		t.Errorf("mode = %v, want -rwxr-xr-x", info.Mode().Perm())
+	}
+	if _, err := os.Stat(script + ".changelog-tmp"); !os.IsNotExist(err) {
+		t.Error("the temporary file was left behind")
+	}
+}
+
+func TestRestoreTargetChangesRemovesCreatedFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	changes := []*TargetChange{{Path: path, Updated: "# Changelog\n", Created: true}}
+	if err := ApplyTargetChanges(changes); err != nil {
+		t.Fatal(err)
+	}
+	if err := RestoreTargetChanges(changes); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("%s should be removed, got %v", path, err)
+	}
+}
@@ -0,0 +1,104 @@
+package lib
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Trailer is a "Key: value" line at the end of a commit message (e.g. Co-authored-by)
+type Trailer struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// trailerLine matches a "Key: value" trailer line, the "BREAKING CHANGE: value"
+// footer, and the "Key #value" form conventional commits use for references
+var trailerLine = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*|BREAKING CHANGE)(?::\s+(.+)|\s+(#.+))$`)
+
+// knownTrailers are trailers that mark the last paragraph as a footer even when
+// some of its lines are ordinary text
+var knownTrailers = map[string]bool{
+	"signed-off-by":   true,
+	"co-authored-by":  true,
+	"reviewed-by":     true,
+	"acked-by":        true,
+	"tested-by":       true,
+	"reported-by":     true,
+	"breaking change": true,
+	"breaking-change": true,
+}
+
+// ParseTrailers returns the trailers from the last paragraph of a commit message.
+// The subject line is never treated as a trailer block.
+func ParseTrailers(message string) []Trailer {
+	paragraphs := splitParagraphs(message)
+	if len(paragraphs) < 2 {
+		return nil
+	}
+	trailers, _, _ := trailerBlock(paragraphs[len(paragraphs)-1])
+	return trailers
+}
+
+// trailerBlock reads a paragraph as a block of trailers, returning them and the
+// lines that aren't trailers. Like git interpret-trailers, the paragraph is a
+// trailer block when every line is a trailer, or when at least a quarter are and
+// one of them is a known trailer such as Signed-off-by; otherwise ok is false.
+// Indented lines continue the trailer above them.
+func trailerBlock(paragraph string) (trailers []Trailer, rest []string, ok bool) {
+	known, continues := false, false
+	lines := strings.Split(paragraph, "\n")
+	trailerLines := 0
+	for _, line := range lines {
+		if continues && line != strings.TrimLeft(line, " \t") {
+			last := &trailers[len(trailers)-1]
+			last.Value += " " + strings.TrimSpace(line)
+			trailerLines++
+			continue
+		}
+		match := trailerLine.FindStringSubmatch(strings.TrimSpace(line))
+		continues = match != nil
+		if match == nil {
+			rest = append(rest, line)
+			continue
+		}
+		trailers = append(trailers, Trailer{Key: match[1], Value: strings.TrimSpace(match[2] + match[3])})
+		known = known || knownTrailers[strings.ToLower(match[1])]
+		trailerLines++
+	}
+
+	if len(trailers) == 0 || (len(rest) > 0 && (!known || trailerLines*4 < len(lines))) {
+		return nil, nil, false
+	}
+	return trailers, rest, true
+}
+
+// TrailerValues returns the values of all trailers with the given key (case-insensitive)
+func TrailerValues(trailers []Trailer, key string) []string {
+	var values []string
+	for _, trailer := range trailers {
+		if strings.EqualFold(trailer.Key, key) {
+			values = append(values, trailer.Value)
+		}
+	}
+	return values
+}
+
+// splitParagraphs splits a message on blank lines, dropping empty paragraphs
+func splitParagraphs(message string) []string {
+	var paragraphs []string
+	var current []string
+	for _, line := range strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				paragraphs = append(paragraphs, strings.Join(current, "\n"))
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, strings.Join(current, "\n"))
+	}
+	return paragraphs
+}
@@ -0,0 +1,157 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// VersionStrategy decides where the version of a generated changelog comes from
+type VersionStrategy string
+
+const (
+	// StrategyTag uses the latest version tag and the commits leading up to it.
+	// Without any version tag, all commits are unreleased.
+	StrategyTag VersionStrategy = "tag"
+	// StrategyNext calculates the next version from the commits since the last release
+	StrategyNext VersionStrategy = "next"
+	// StrategyFlag uses a version given on the command line for the unreleased commits
+	StrategyFlag VersionStrategy = "flag"
+	// StrategyConfig uses project.version from the config file
+	StrategyConfig VersionStrategy = "config"
+)
+
+// ResolvedVersion is the version of a changelog and the commit range it covers
+type ResolvedVersion struct {
+	Strategy    VersionStrategy
+	Version     string
+	Tag         string      // tag of this version; empty if it hasn't been tagged yet
+	PreviousTag *VersionTag // release before this one, nil if none
+	From        plumbing.Hash
+	To          plumbing.Hash
+	Date        time.Time // tag date, or the HEAD commit date for untagged versions
+}
+
+// HasRange reports whether the version determines which commits belong in the changelog
+func (r *ResolvedVersion) HasRange() bool {
+	return r.Strategy != StrategyConfig
+}
+
+// ResolveVersion works out the changelog version using the configured strategy.
+// A non-empty explicit version always wins (the "flag" strategy).
+func ResolveVersion(repo *git.Repository, config *Config, explicit string) (*ResolvedVersion, error) {
+	strategy := VersionStrategy(strings.ToLower(config.Versioning.Strategy))
+	if explicit != "" {
+		strategy = StrategyFlag
+	}
+	if strategy == "" {
+		// Older configs only have project.version
+		strategy = StrategyConfig
+		if config.Project.Version == "" {
+			strategy = StrategyTag
+		}
+	}
+
+	prefix := config.Versioning.TagPrefix
+	resolved := &ResolvedVersion{Strategy: strategy}
+
+	scheme, err := NewVersionScheme(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Untagged versions are dated by HEAD rather than the clock
+	head, err := repo.Head()
+	if strategy == StrategyConfig {
+		resolved.Version = config.Project.Version
+		if err == nil {
+			resolved.Date, _ = CommitDate(repo, head.Hash())
+		}
+		return resolved, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	resolved.To = head.Hash()
+	if resolved.Date, err = CommitDate(repo, head.Hash()); err != nil {
+		return nil, err
+	}
+
+	tags, err := FindVersionTags(repo, prefix, scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strategy {
+	case StrategyTag:
+		latest := LatestVersionTag(tags, true)
+		if latest == nil {
+			// Nothing is tagged yet, so every commit is unreleased
+			break
+		}
+		resolved.Version = latest.Version.String()
+		resolved.Tag = latest.Name
+		resolved.To = latest.Hash
+		resolved.Date = latest.Date
+		resolved.PreviousTag = previousVersionTag(tags, latest, scheme)
+
+	case StrategyNext:
+		classifier, err := NewClassifier(repo, config)
+		if err != nil {
+			return nil, err
+		}
+		result, err := CalculateNextVersion(repo, NextVersionOptions{TagPrefix: prefix, Scheme: scheme, Classifier: classifier})
+		if err != nil {
+			return nil, err
+		}
+		resolved.Version = result.Next.String()
+		resolved.PreviousTag = result.Previous
+
+	case StrategyFlag:
+		version, err := scheme.Parse(strings.TrimPrefix(explicit, prefix))
+		if err != nil {
+			return nil, err
+		}
+		resolved.Version = version.String()
+
+		// An already tagged version is regenerated from its tag
+		for _, tag := range tags {
+			if scheme.Compare(tag.Version, version) == 0 {
+				resolved.Tag = tag.Name
+				resolved.To = tag.Hash
+				resolved.Date = tag.Date
+				resolved.PreviousTag = previousVersionTag(tags, tag, scheme)
+				break
+			}
+		}
+		if resolved.Tag == "" {
+			resolved.PreviousTag = LatestVersionTag(tags, version.IsPreRelease())
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown version strategy %q (use tag, next, flag or config)", strategy)
+	}
+
+	if resolved.PreviousTag != nil {
+		resolved.From = resolved.PreviousTag.Hash
+	}
+	return resolved, nil
+}
+
+// previousVersionTag finds the release before the given tag. Final releases
+// skip over pre-releases so their section covers everything since the last final release.
+func previousVersionTag(tags []*VersionTag, current *VersionTag, scheme VersionScheme) *VersionTag {
+	for _, tag := range tags {
+		if scheme.Compare(tag.Version, current.Version) >= 0 {
+			continue
+		}
+		if !current.Version.IsPreRelease() && tag.Version.IsPreRelease() {
+			continue
+		}
+		return tag
+	}
+	return nil
+}